@@ -0,0 +1,77 @@
+// Command rpctl manages ReportPortal project from command line.
+//
+// Usage:
+//
+//	rpctl dashboards sync -f dashboards.yaml [-prune] [-dry-run]
+//
+// Connection is configured with -endpoint, -project and -token flags
+// or RP_ENDPOINT, RP_PROJECT and RP_TOKEN environment variables
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/igorexec/client-go/rp"
+	"github.com/igorexec/client-go/rp/dashsync"
+)
+
+const usage = `Usage:
+  rpctl dashboards sync -f <spec> [-prune] [-dry-run]
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "rpctl:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes subcommand specified by args
+func run(args []string, out io.Writer) error {
+	if len(args) < 2 || args[0] != "dashboards" || args[1] != "sync" {
+		return fmt.Errorf("unknown command\n%s", usage)
+	}
+	return syncDashboards(args[2:], out)
+}
+
+// syncDashboards reconciles dashboards of project with spec
+func syncDashboards(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("dashboards sync", flag.ContinueOnError)
+	endpoint := fs.String("endpoint", os.Getenv("RP_ENDPOINT"), "ReportPortal endpoint")
+	project := fs.String("project", os.Getenv("RP_PROJECT"), "ReportPortal project")
+	token := fs.String("token", os.Getenv("RP_TOKEN"), "ReportPortal token")
+	apiVersion := fs.Int("api-version", 1, "ReportPortal API version")
+	spec := fs.String("f", "", "path to YAML or JSON spec")
+	prune := fs.Bool("prune", false, "delete filters, dashboards and widgets of current user missing in spec")
+	dryRun := fs.Bool("dry-run", false, "print changes without applying them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *spec == "" {
+		return fmt.Errorf("spec is not specified\n%s", usage)
+	}
+	if *endpoint == "" || *project == "" || *token == "" {
+		return fmt.Errorf("endpoint, project and token must be specified")
+	}
+
+	s, err := dashsync.Load(*spec)
+	if err != nil {
+		return err
+	}
+
+	c := rp.NewClient(*endpoint, *project, *token, *apiVersion)
+	changes, err := dashsync.Sync(c, s, dashsync.Options{Prune: *prune, DryRun: *dryRun})
+	for _, ch := range changes {
+		fmt.Fprintln(out, ch)
+	}
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(out, "no changes")
+	}
+	return nil
+}
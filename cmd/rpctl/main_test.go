@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	t.Run("Unknown command", func(t *testing.T) {
+		err := run([]string{"launches"}, ioutil.Discard)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown command")
+	})
+
+	t.Run("Dry run", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "GET", r.Method)
+			switch r.URL.Path {
+			case "/api/v1/p/filter":
+				w.Write([]byte(`{"content":[]}`))
+			case "/api/v1/p/dashboard":
+				w.Write([]byte(`[]`))
+			}
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		dir, err := ioutil.TempDir("", "rpctl")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+		spec := filepath.Join(dir, "spec.yaml")
+		assert.NoError(t, ioutil.WriteFile(spec, []byte("dashboards: [{name: Nightly}]"), 0644))
+
+		var out bytes.Buffer
+		err = run([]string{"dashboards", "sync", "-endpoint", s.URL, "-project", "p", "-token", "t", "-f", spec, "-dry-run"}, &out)
+		assert.NoError(t, err)
+		assert.Equal(t, "+ dashboard \"Nightly\"\n", out.String())
+	})
+}
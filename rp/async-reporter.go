@@ -0,0 +1,317 @@
+package rp
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Future defines result of asynchronous operation
+type Future struct {
+	done chan struct{}
+	err  error
+
+	// guarded by mutex of reporter
+	resolved   bool
+	dependents []*operation
+}
+
+// newFuture creates new unresolved future
+func newFuture() *Future {
+	return &Future{done: make(chan struct{})}
+}
+
+// resolve completes future with specified error
+func (f *Future) resolve(err error) {
+	f.err = err
+	close(f.done)
+}
+
+// Done returns channel which is closed when operation completes
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait waits for operation to complete and returns its error
+func (f *Future) Wait() error {
+	<-f.done
+	return f.err
+}
+
+// AsyncReporter reports launches, test items and logs without blocking the caller.
+// Operations on the same launch or test item are executed in order they were called,
+// test items are started after their parents and finished after all operations on their children
+type AsyncReporter struct {
+	concurrency int
+
+	mu       sync.Mutex
+	closed   bool
+	errs     MultiError
+	workers  int
+	queue    []*operation
+	pending  int
+	idle     chan struct{}
+	tails    map[interface{}]*Future
+	starts   map[interface{}]*Future
+	parents  map[interface{}]interface{}
+	children map[interface{}][]interface{}
+}
+
+const (
+	opStart = iota
+	opUpdate
+	opFinish
+)
+
+// operation defines operation on launch or test item waiting for its prerequisites
+type operation struct {
+	key     interface{}
+	kind    int
+	fn      func() error
+	future  *Future
+	deps    []*Future
+	waiting int
+}
+
+// NewAsyncReporter creates new asynchronous reporter which executes at most concurrency requests at once
+func NewAsyncReporter(concurrency int) *AsyncReporter {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &AsyncReporter{
+		concurrency: concurrency,
+		tails:       make(map[interface{}]*Future),
+		starts:      make(map[interface{}]*Future),
+		parents:     make(map[interface{}]interface{}),
+		children:    make(map[interface{}][]interface{}),
+	}
+}
+
+// StartLaunch starts launch asynchronously, launch id is available when returned future completes
+func (r *AsyncReporter) StartLaunch(l *Launch) *Future {
+	return r.enqueue(l, nil, opStart, l.Start)
+}
+
+// FinishLaunch finishes launch asynchronously after all operations on its test items
+func (r *AsyncReporter) FinishLaunch(l *Launch, status string) *Future {
+	return r.enqueue(l, nil, opFinish, func() error {
+		return l.Finish(status)
+	})
+}
+
+// StartTestItem starts test item asynchronously after its launch and parent are started,
+// test item id is available when returned future completes
+func (r *AsyncReporter) StartTestItem(ti *TestItem) *Future {
+	parent := interface{}(ti.launch)
+	if ti.Parent != nil {
+		parent = ti.Parent
+	}
+	return r.enqueue(ti, parent, opStart, ti.Start)
+}
+
+// FinishTestItem finishes test item asynchronously after all operations on its children
+func (r *AsyncReporter) FinishTestItem(ti *TestItem, status string) *Future {
+	return r.enqueue(ti, nil, opFinish, func() error {
+		return ti.Finish(status)
+	})
+}
+
+// Log sends log for test item asynchronously
+func (r *AsyncReporter) Log(ti *TestItem, message, level string, attachment *Attachment) *Future {
+	return r.enqueue(ti, nil, opUpdate, func() error {
+		return ti.Log(message, level, attachment)
+	})
+}
+
+// Flush waits for all queued operations to complete and returns errors occurred since previous flush
+func (r *AsyncReporter) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	idle := r.idle
+	r.mu.Unlock()
+
+	if idle != nil {
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	r.mu.Lock()
+	errs := r.errs
+	r.errs = nil
+	r.mu.Unlock()
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Close stops accepting new operations and waits for queued operations to complete
+func (r *AsyncReporter) Close() error {
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+	return r.Flush(context.Background())
+}
+
+// enqueue schedules fn as operation on launch or test item identified by key.
+// Operation is executed after previous operation on the key, starts of the key and parent,
+// and, unless it starts the key, after all operations on children of the key.
+// Operations depending on failed start are not executed
+func (r *AsyncReporter) enqueue(key, parent interface{}, kind int, fn func() error) *Future {
+	f := newFuture()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		f.resolved = true
+		f.resolve(errors.New("reporter is closed"))
+		return f
+	}
+
+	op := &operation{key: key, kind: kind, fn: fn, future: f}
+	// waits ignore errors, deps prevent execution when failed
+	var waits []*Future
+	if prev := r.tails[key]; prev != nil {
+		waits = append(waits, prev)
+	}
+	if kind == opStart {
+		r.starts[key] = f
+		if parent != nil {
+			r.parents[key] = parent
+			r.children[parent] = append(r.children[parent], key)
+			if ps := r.starts[parent]; ps != nil {
+				op.deps = append(op.deps, ps)
+			}
+		}
+	} else {
+		if s := r.starts[key]; s != nil {
+			op.deps = append(op.deps, s)
+		}
+		for _, child := range r.children[key] {
+			if t := r.tails[child]; t != nil {
+				waits = append(waits, t)
+			}
+		}
+	}
+	r.tails[key] = f
+
+	for _, p := range append(waits, op.deps...) {
+		if !p.resolved {
+			op.waiting++
+			p.dependents = append(p.dependents, op)
+		}
+	}
+
+	if r.pending == 0 {
+		r.idle = make(chan struct{})
+	}
+	r.pending++
+	if op.waiting == 0 {
+		r.schedule(op)
+	}
+	return f
+}
+
+// schedule queues operation which prerequisites completed and starts worker
+// when less than concurrency workers run. Must be called with locked mutex
+func (r *AsyncReporter) schedule(op *operation) {
+	r.queue = append(r.queue, op)
+	if r.workers < r.concurrency {
+		r.workers++
+		go r.work()
+	}
+}
+
+// work executes queued operations until queue is empty
+func (r *AsyncReporter) work() {
+	for {
+		r.mu.Lock()
+		if len(r.queue) == 0 {
+			r.workers--
+			r.mu.Unlock()
+			return
+		}
+		op := r.queue[0]
+		r.queue[0] = nil
+		r.queue = r.queue[1:]
+		r.mu.Unlock()
+
+		executed, err := op.run()
+		r.complete(op, executed, err)
+	}
+}
+
+// run executes operation unless one of its dependencies failed
+func (op *operation) run() (executed bool, err error) {
+	for _, d := range op.deps {
+		if d.err != nil {
+			return false, errors.Wrap(d.err, "dependent operation failed")
+		}
+	}
+	return true, op.fn()
+}
+
+// complete resolves future of operation, schedules operations waiting for it
+// and forgets state of launch or test item which is no longer needed
+func (r *AsyncReporter) complete(op *operation, executed bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if executed && err != nil {
+		r.errs = append(r.errs, err)
+	}
+
+	f := op.future
+	f.resolved = true
+	f.resolve(err)
+	for _, d := range f.dependents {
+		d.waiting--
+		if d.waiting == 0 {
+			r.schedule(d)
+		}
+	}
+	f.dependents = nil
+
+	if r.tails[op.key] == f {
+		delete(r.tails, op.key)
+	}
+	switch {
+	case op.kind == opStart && err == nil && r.starts[op.key] == f:
+		delete(r.starts, op.key)
+	case op.kind == opFinish:
+		r.forget(op.key)
+	}
+
+	r.pending--
+	if r.pending == 0 {
+		close(r.idle)
+		r.idle = nil
+	}
+}
+
+// forget removes state of finished launch or test item. Must be called with locked mutex
+func (r *AsyncReporter) forget(key interface{}) {
+	delete(r.starts, key)
+	delete(r.children, key)
+	parent, ok := r.parents[key]
+	if !ok {
+		return
+	}
+	delete(r.parents, key)
+	siblings := r.children[parent]
+	for i, k := range siblings {
+		if k == key {
+			siblings = append(siblings[:i], siblings[i+1:]...)
+			break
+		}
+	}
+	if len(siblings) == 0 {
+		delete(r.children, parent)
+	} else {
+		r.children[parent] = siblings
+	}
+}
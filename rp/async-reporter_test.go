@@ -0,0 +1,203 @@
+package rp
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// asyncServer responds with random delay and records handled requests
+type asyncServer struct {
+	mu       sync.Mutex
+	count    int
+	requests []string
+}
+
+func (as *asyncServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(time.Duration(rand.Intn(5)) * time.Millisecond)
+
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	as.requests = append(as.requests, r.Method+" "+r.URL.Path)
+
+	if r.Method == "POST" && r.URL.Path != "/test_project/log" {
+		as.count++
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id": "id%d"}`, as.count)
+		return
+	}
+	if r.Method == "POST" {
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func (as *asyncServer) index(request string) int {
+	for i, r := range as.requests {
+		if r == request {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestAsyncReporter(t *testing.T) {
+	t.Run("Successful report", func(t *testing.T) {
+		as := &asyncServer{}
+		s := httptest.NewServer(as)
+		defer s.Close()
+
+		c := &Client{Endpoint: s.URL, Project: "test_project"}
+		l := NewLaunch(c, "launch", "", ModeDefault, nil)
+		suite := NewTestItem(l, "suite", "", TestItemSuite, nil, nil)
+		test := NewTestItem(l, "test", "", TestItemTest, nil, suite)
+
+		r := NewAsyncReporter(4)
+		r.StartLaunch(l)
+		r.StartTestItem(suite)
+		testStarted := r.StartTestItem(test)
+		r.Log(test, "message", LevelInfo, nil)
+		r.FinishTestItem(test, StatusPassed)
+		r.FinishTestItem(suite, StatusPassed)
+		r.FinishLaunch(l, StatusPassed)
+
+		assert.NoError(t, testStarted.Wait())
+		assert.NotEmpty(t, test.Id)
+		assert.NoError(t, r.Close())
+
+		assert.Len(t, as.requests, 7)
+		assert.Equal(t, "POST /test_project/launch", as.requests[0])
+		assert.Equal(t, "POST /test_project/item", as.requests[1])
+		assert.Equal(t, "POST /test_project/item/"+suite.Id, as.requests[2])
+		assert.Equal(t, "POST /test_project/log", as.requests[3])
+		assert.True(t, as.index("PUT /test_project/item/"+test.Id) < as.index("PUT /test_project/item/"+suite.Id))
+		assert.Equal(t, "PUT /test_project/launch/"+l.Id+"/finish", as.requests[6])
+
+		f := r.StartLaunch(l)
+		assert.EqualError(t, f.Wait(), "reporter is closed")
+	})
+
+	t.Run("Failed start", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{Endpoint: s.URL, Project: "test_project"}
+		l := NewLaunch(c, "launch", "", ModeDefault, nil)
+		ti := NewTestItem(l, "test", "", TestItemTest, nil, nil)
+
+		r := NewAsyncReporter(1)
+		r.StartLaunch(l)
+		started := r.StartTestItem(ti)
+		logged := r.Log(ti, "message", LevelInfo, nil)
+
+		err := r.Flush(context.Background())
+		assert.EqualError(t, err, "failed with status 500 Internal Server Error")
+		assert.True(t, strings.HasPrefix(started.Wait().Error(), "dependent operation failed"))
+		assert.True(t, strings.HasPrefix(logged.Wait().Error(), "dependent operation failed"))
+	})
+
+	t.Run("Flush timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": "id1"}`))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		l := NewLaunch(&Client{Endpoint: s.URL, Project: "test_project"}, "launch", "", ModeDefault, nil)
+
+		r := NewAsyncReporter(1)
+		r.StartLaunch(l)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, r.Flush(ctx))
+
+		close(release)
+		assert.NoError(t, r.Close())
+	})
+
+	t.Run("Bounded concurrency", func(t *testing.T) {
+		var mu sync.Mutex
+		var running, maxRunning int
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			if r.Method == "POST" {
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id": "id"}`))
+			}
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{Endpoint: s.URL, Project: "test_project"}
+		l := NewLaunch(c, "launch", "", ModeDefault, nil)
+
+		r := NewAsyncReporter(2)
+		r.StartLaunch(l)
+		for i := 0; i < 20; i++ {
+			ti := NewTestItem(l, fmt.Sprintf("test%d", i), "", TestItemTest, nil, nil)
+			r.StartTestItem(ti)
+			r.FinishTestItem(ti, StatusPassed)
+		}
+		r.FinishLaunch(l, StatusPassed)
+		assert.NoError(t, r.Close())
+		assert.Equal(t, 2, maxRunning)
+
+		assert.Empty(t, r.tails)
+		assert.Empty(t, r.starts)
+		assert.Empty(t, r.parents)
+		assert.Empty(t, r.children)
+		assert.Equal(t, 0, r.workers)
+	})
+
+	t.Run("Concurrent enqueue and flush", func(t *testing.T) {
+		as := &asyncServer{}
+		s := httptest.NewServer(as)
+		defer s.Close()
+
+		c := &Client{Endpoint: s.URL, Project: "test_project"}
+		r := NewAsyncReporter(4)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				l := NewLaunch(c, "launch", "", ModeDefault, nil)
+				r.StartLaunch(l)
+				r.FinishLaunch(l, StatusPassed)
+			}()
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, r.Flush(context.Background()))
+			}()
+		}
+		wg.Wait()
+		assert.NoError(t, r.Close())
+		assert.Len(t, as.requests, 8)
+	})
+}
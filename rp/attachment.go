@@ -0,0 +1,84 @@
+package rp
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// AttachmentFromFile creates attachment with content of file, name of the file and mime type
+// detected by file extension. File is closed when log with attachment is sent or rejected,
+// attachment which isn't logged must be closed with Close
+func AttachmentFromFile(path string) (*Attachment, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open file %s", path)
+	}
+
+	name := filepath.Base(path)
+	return &Attachment{
+		Name:     name,
+		Data:     f,
+		MimeType: mime.TypeByExtension(filepath.Ext(name)),
+	}, nil
+}
+
+// AttachmentFromBytes creates attachment with data and mime type detected by name extension or content.
+// Empty name is replaced by "attachment" with extension of detected mime type
+func AttachmentFromBytes(name string, data []byte) *Attachment {
+	mimeType := mime.TypeByExtension(filepath.Ext(name))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	if name == "" {
+		name = "attachment"
+		if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+
+	return &Attachment{
+		Name:     name,
+		Data:     bytes.NewReader(data),
+		MimeType: mimeType,
+	}
+}
+
+// AttachmentFromJSON creates attachment with value marshaled as indented JSON.
+// Empty name is replaced by "data.json"
+func AttachmentFromJSON(name string, v interface{}) (*Attachment, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal object %v", v)
+	}
+	if name == "" {
+		name = "data.json"
+	}
+
+	return &Attachment{
+		Name:     name,
+		Data:     bytes.NewReader(b),
+		MimeType: "application/json",
+	}, nil
+}
+
+// AttachmentFromResponse creates text attachment with dump of HTTP response including its body.
+// Body of response can be read again after dump
+func AttachmentFromResponse(resp *http.Response) (*Attachment, error) {
+	b, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to dump response")
+	}
+
+	return &Attachment{
+		Name:     "response.txt",
+		Data:     bytes.NewReader(b),
+		MimeType: "text/plain",
+	}, nil
+}
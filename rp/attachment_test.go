@@ -0,0 +1,144 @@
+package rp
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachmentFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rp")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "report.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"ok":true}`), 0644))
+
+	a, err := AttachmentFromFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "report.json", a.Name)
+	assert.Equal(t, "application/json", a.MimeType)
+
+	d, err := ioutil.ReadAll(a.Data)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"ok":true}`, string(d))
+	assert.NoError(t, a.Close())
+
+	_, err = AttachmentFromFile(filepath.Join(dir, "missing.txt"))
+	assert.Error(t, err)
+}
+
+func TestAttachmentFromBytes(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+
+	a := AttachmentFromBytes("", png)
+	assert.Equal(t, "attachment.png", a.Name)
+	assert.Equal(t, "image/png", a.MimeType)
+
+	a = AttachmentFromBytes("screen.png", png)
+	assert.Equal(t, "screen.png", a.Name)
+	assert.Equal(t, "image/png", a.MimeType)
+
+	a = AttachmentFromBytes("output", []byte("plain text"))
+	assert.Equal(t, "output", a.Name)
+	assert.Equal(t, "text/plain; charset=utf-8", a.MimeType)
+}
+
+func TestAttachmentFromJSON(t *testing.T) {
+	a, err := AttachmentFromJSON("", map[string]int{"a": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "data.json", a.Name)
+	assert.Equal(t, "application/json", a.MimeType)
+
+	d, err := ioutil.ReadAll(a.Data)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\": 1\n}", string(d))
+
+	_, err = AttachmentFromJSON("", make(chan int))
+	assert.Error(t, err)
+}
+
+func TestAttachmentFromResponse(t *testing.T) {
+	resp := &http.Response{
+		StatusCode:    http.StatusNotFound,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"text/plain"}},
+		Body:          ioutil.NopCloser(strings.NewReader("not found")),
+		ContentLength: 9,
+	}
+
+	a, err := AttachmentFromResponse(resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "response.txt", a.Name)
+
+	d, err := ioutil.ReadAll(a.Data)
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(d, []byte("HTTP/1.1 404 Not Found\r\n")))
+	assert.True(t, bytes.HasSuffix(d, []byte("\r\n\r\nnot found")))
+
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "not found", string(body))
+}
+
+// closeCounter counts Close calls of attachment data
+type closeCounter struct {
+	*strings.Reader
+	closed int
+}
+
+func (cc *closeCounter) Close() error {
+	cc.closed++
+	return nil
+}
+
+func TestAttachmentClosed(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"responses":[{"id":"l1"}]}`))
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	unreachable := httptest.NewServer(h)
+	unreachable.Close()
+
+	cases := []struct {
+		name    string
+		client  *Client
+		level   Level
+		success bool
+	}{
+		{"Sent", &Client{Endpoint: s.URL}, LevelInfo, true},
+		{"Invalid level", &Client{Endpoint: s.URL}, "loud", false},
+		{"Size limit", &Client{Endpoint: s.URL, MaxAttachmentSize: 2}, LevelInfo, false},
+		{"Transport error", &Client{Endpoint: unreachable.URL}, LevelInfo, false},
+		{"Batch", &Client{Endpoint: s.URL}, LevelInfo, true},
+		{"Batch invalid level", &Client{Endpoint: s.URL}, "loud", false},
+		{"Batch size limit", &Client{Endpoint: s.URL, MaxAttachmentSize: 2}, LevelInfo, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if strings.HasPrefix(tc.name, "Batch") {
+				tc.client.LogBatcher = NewLogBatcher(tc.client, 0, 0, 0)
+			}
+			data := &closeCounter{Reader: strings.NewReader("content")}
+			err := tc.client.SendLog(&LogEntry{
+				Message:    "message",
+				Level:      tc.level,
+				ItemId:     "item",
+				Attachment: &Attachment{Name: "a.txt", Data: data, MimeType: "text/plain"},
+			})
+			assert.Equal(t, tc.success, err == nil, "%v", err)
+			assert.Equal(t, 1, data.closed)
+		})
+	}
+}
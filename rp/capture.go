@@ -0,0 +1,249 @@
+package rp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// capturing is set while CaptureOutput redirects output of the process
+var capturing struct {
+	sync.Mutex
+	active bool
+}
+
+// OutputCapture sends lines written to its stdout and stderr writers as logs of test item.
+// Lines above the limit are folded into text attachments sent on Close
+type OutputCapture struct {
+	item     *TestItem
+	maxLines int
+
+	mu     sync.Mutex
+	lines  int
+	folded map[string]*foldedOutput
+	errs   MultiError
+	stdout *lineWriter
+	stderr *lineWriter
+}
+
+// foldedOutput defines lines of stream which weren't sent as separate logs
+type foldedOutput struct {
+	level string
+	lines int
+	buf   bytes.Buffer
+}
+
+// lineWriter splits written data into lines of stream
+type lineWriter struct {
+	oc     *OutputCapture
+	stream string
+	level  string
+	buf    []byte
+}
+
+// NewOutputCapture creates new output capture for test item which sends at most maxLines lines
+// as separate logs, zero maxLines means no limit
+func NewOutputCapture(item *TestItem, maxLines int) *OutputCapture {
+	oc := &OutputCapture{
+		item:     item,
+		maxLines: maxLines,
+		folded:   make(map[string]*foldedOutput),
+	}
+	oc.stdout = &lineWriter{oc: oc, stream: "stdout", level: LevelInfo}
+	oc.stderr = &lineWriter{oc: oc, stream: "stderr", level: LevelError}
+	return oc
+}
+
+// Stdout returns writer for standard output, lines are sent with info level
+func (oc *OutputCapture) Stdout() io.Writer {
+	return oc.stdout
+}
+
+// Stderr returns writer for standard error, lines are sent with error level
+func (oc *OutputCapture) Stderr() io.Writer {
+	return oc.stderr
+}
+
+// Close sends incomplete last lines and folded output. Returns errors of all sent logs
+func (oc *OutputCapture) Close() error {
+	oc.mu.Lock()
+	var last [2][]string
+	for i, lw := range []*lineWriter{oc.stdout, oc.stderr} {
+		if len(lw.buf) > 0 && !oc.fold(lw, string(lw.buf)) {
+			last[i] = append(last[i], string(lw.buf))
+		}
+		lw.buf = nil
+	}
+	folded := oc.folded
+	oc.folded = make(map[string]*foldedOutput)
+	oc.mu.Unlock()
+
+	oc.send(oc.stdout.level, last[0])
+	oc.send(oc.stderr.level, last[1])
+
+	for _, stream := range []string{"stdout", "stderr"} {
+		f := folded[stream]
+		if f == nil {
+			continue
+		}
+		message := fmt.Sprintf("%d more lines of %s are attached", f.lines, stream)
+		err := oc.item.Log(message, f.level, &Attachment{
+			Name:     stream + ".txt",
+			Data:     &f.buf,
+			MimeType: "text/plain",
+		})
+		oc.addErr(err)
+	}
+
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	if len(oc.errs) > 0 {
+		return oc.errs
+	}
+	return nil
+}
+
+// Write sends every complete line of p as log. Logs are sent after lock of capture is released
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	lw.oc.mu.Lock()
+	lw.buf = append(lw.buf, p...)
+	var lines []string
+	for {
+		i := bytes.IndexByte(lw.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(bytes.TrimSuffix(lw.buf[:i], []byte("\r")))
+		if !lw.oc.fold(lw, line) {
+			lines = append(lines, line)
+		}
+		lw.buf = lw.buf[i+1:]
+	}
+	lw.oc.mu.Unlock()
+
+	lw.oc.send(lw.level, lines)
+	return len(p), nil
+}
+
+// send sends lines as logs with specified level
+func (oc *OutputCapture) send(level string, lines []string) {
+	for _, line := range lines {
+		oc.addErr(oc.item.Log(line, level, nil))
+	}
+}
+
+// addErr records error of sent log
+func (oc *OutputCapture) addErr(err error) {
+	oc.mu.Lock()
+	oc.errs = appendErr(oc.errs, err)
+	oc.mu.Unlock()
+}
+
+// fold counts line and folds it when limit of lines is reached. Returns false when line
+// must be sent as log. Must be called with locked mutex
+func (oc *OutputCapture) fold(lw *lineWriter, line string) bool {
+	oc.lines++
+	if oc.maxLines <= 0 || oc.lines <= oc.maxLines {
+		return false
+	}
+
+	f := oc.folded[lw.stream]
+	if f == nil {
+		f = &foldedOutput{level: lw.level}
+		oc.folded[lw.stream] = f
+	}
+	f.lines++
+	f.buf.WriteString(line)
+	f.buf.WriteByte('\n')
+	return true
+}
+
+// CaptureOutput redirects os.Stdout and os.Stderr while fn runs and sends written lines as logs
+// of test item. Output of standard logger is captured as stderr unless its output was changed from
+// os.Stderr. Output is still written to original stdout and stderr. Writes to file descriptors 1 and 2
+// bypassing os.Stdout and os.Stderr (e.g. by cgo code or child processes) aren't captured, use
+// CaptureCmd for commands. Output of the whole process is captured, so it isn't safe for parallel
+// tests, and only one capture can be active at once: nested or concurrent calls return error
+// without running fn
+func (ti *TestItem) CaptureOutput(maxLines int, fn func()) (err error) {
+	capturing.Lock()
+	if capturing.active {
+		capturing.Unlock()
+		return errors.New("output is already captured")
+	}
+	capturing.active = true
+	capturing.Unlock()
+	defer func() {
+		capturing.Lock()
+		capturing.active = false
+		capturing.Unlock()
+	}()
+
+	oc := NewOutputCapture(ti, maxLines)
+
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		return errors.Wrap(err, "failed to create pipe for stdout")
+	}
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		outR.Close()
+		outW.Close()
+		return errors.Wrap(err, "failed to create pipe for stderr")
+	}
+
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outW, errW
+	// standard logger keeps os.Stderr it was created with
+	logOutput := log.Writer()
+	if logOutput == stderr {
+		log.SetOutput(errW)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		io.Copy(io.MultiWriter(stdout, oc.Stdout()), outR)
+	}()
+	go func() {
+		defer wg.Done()
+		io.Copy(io.MultiWriter(stderr, oc.Stderr()), errR)
+	}()
+
+	defer func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		log.SetOutput(logOutput)
+		outW.Close()
+		errW.Close()
+		wg.Wait()
+		outR.Close()
+		errR.Close()
+		err = oc.Close()
+	}()
+
+	fn()
+	return nil
+}
+
+// CaptureCmd runs command and sends lines of its stdout and stderr as logs of test item
+func (ti *TestItem) CaptureCmd(cmd *exec.Cmd, maxLines int) error {
+	oc := NewOutputCapture(ti, maxLines)
+	cmd.Stdout = oc.Stdout()
+	cmd.Stderr = oc.Stderr()
+
+	runErr := cmd.Run()
+	if err := oc.Close(); err != nil {
+		return errors.Wrap(err, "failed to send output")
+	}
+	if runErr != nil {
+		return errors.Wrapf(runErr, "failed to run %s", cmd.Path)
+	}
+	return nil
+}
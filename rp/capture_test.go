@@ -0,0 +1,135 @@
+package rp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputCapture(t *testing.T) {
+	var mu sync.Mutex
+	var logs []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		mu.Lock()
+		logs = append(logs, string(d))
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	ti := &TestItem{
+		Id: "id123",
+		client: &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		},
+	}
+
+	t.Run("Fold lines above limit", func(t *testing.T) {
+		logs = nil
+		oc := NewOutputCapture(ti, 2)
+		fmt.Fprint(oc.Stdout(), "first\nsec")
+		fmt.Fprint(oc.Stdout(), "ond\r\nthird\nfourth")
+		fmt.Fprint(oc.Stderr(), "error\n")
+		assert.NoError(t, oc.Close())
+
+		assert.Len(t, logs, 4)
+		assert.Contains(t, logs[0], `"message":"first","level":"info"`)
+		assert.Contains(t, logs[1], `"message":"second","level":"info"`)
+		assert.Contains(t, logs[2], `"message":"2 more lines of stdout are attached"`)
+		assert.Contains(t, logs[2], `filename="stdout.txt"`)
+		assert.Contains(t, logs[2], "third\nfourth\n")
+		assert.Contains(t, logs[3], `"level":"error","message":"1 more lines of stderr are attached"`)
+		assert.Contains(t, logs[3], "error\n")
+	})
+
+	t.Run("Capture process output", func(t *testing.T) {
+		logs = nil
+		err := ti.CaptureOutput(0, func() {
+			fmt.Println("to stdout")
+			fmt.Fprintln(os.Stderr, "to stderr")
+		})
+		assert.NoError(t, err)
+
+		all := strings.Join(logs, "\n")
+		assert.Len(t, logs, 2)
+		assert.Contains(t, all, `"message":"to stdout","level":"info"`)
+		assert.Contains(t, all, `"message":"to stderr","level":"error"`)
+	})
+
+	t.Run("Capture standard logger", func(t *testing.T) {
+		logs = nil
+		flags := log.Flags()
+		log.SetFlags(0)
+		defer log.SetFlags(flags)
+
+		err := ti.CaptureOutput(0, func() {
+			log.Print("from logger")
+		})
+		assert.NoError(t, err)
+		assert.Len(t, logs, 1)
+		assert.Contains(t, strings.Join(logs, "\n"), `"message":"from logger","level":"error"`)
+		assert.Equal(t, os.Stderr, log.Writer())
+	})
+
+	t.Run("Nested capture", func(t *testing.T) {
+		logs = nil
+		var nestedErr error
+		nestedRun := false
+		err := ti.CaptureOutput(0, func() {
+			nestedErr = ti.CaptureOutput(0, func() { nestedRun = true })
+		})
+		assert.NoError(t, err)
+		assert.EqualError(t, nestedErr, "output is already captured")
+		assert.False(t, nestedRun)
+		assert.NoError(t, ti.CaptureOutput(0, func() {}))
+	})
+
+	t.Run("Logs sent without lock", func(t *testing.T) {
+		var oc *OutputCapture
+		var requests int32
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// writing to capture while its log is being sent must not block
+			if atomic.AddInt32(&requests, 1) == 1 {
+				oc.Stderr().Write([]byte("from server\n"))
+			}
+			w.WriteHeader(http.StatusCreated)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		oc = NewOutputCapture(&TestItem{Id: "id123", client: &Client{Endpoint: s.URL}}, 0)
+		oc.Stdout().Write([]byte("line\n"))
+		assert.NoError(t, oc.Close())
+		assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("Capture command output", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("requires sh")
+		}
+		logs = nil
+		err := ti.CaptureCmd(exec.Command("sh", "-c", "echo out; echo err 1>&2; exit 3"), 0)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "exit status 3")
+
+		all := strings.Join(logs, "\n")
+		assert.Len(t, logs, 2)
+		assert.Contains(t, all, `"message":"out","level":"info"`)
+		assert.Contains(t, all, `"message":"err","level":"error"`)
+	})
+}
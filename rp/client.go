@@ -1,11 +1,16 @@
 package rp
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/pkg/errors"
@@ -15,12 +20,13 @@ const (
 	ModeDebug   = "DEBUG"
 	ModeDefault = "DEFAULT"
 
-	StatusPassed   = "PASSED"
-	StatusFailed   = "FAILED"
-	StatusStopped  = "STOPPED"
-	StatusSkipped  = "SKIPPED"
-	StatusReseted  = "RESETED"
-	StatusCanceled = "CANCELLED"
+	StatusPassed      = "PASSED"
+	StatusFailed      = "FAILED"
+	StatusStopped     = "STOPPED"
+	StatusSkipped     = "SKIPPED"
+	StatusReseted     = "RESETED"
+	StatusCanceled    = "CANCELLED"
+	StatusInterrupted = "INTERRUPTED"
 
 	ActionStop   = "stop"
 	ActionFinish = "finish"
@@ -39,6 +45,26 @@ type Client struct {
 	Endpoint string
 	Token    string
 	Project  string
+
+	// ServerVersion is a major version of ReportPortal server set manually or detected from server,
+	// v5 specific fields (attributes instead of tags, nested steps) are sent only when server supports them
+	ServerVersion int
+	// StatusRollup enables deriving status of test items and launches finished
+	// with empty status from statuses of their children
+	StatusRollup bool
+	// LogBatcher collects logs of test items into batches when set
+	LogBatcher *LogBatcher
+	// MaxAttachmentSize limits size of log attachments in bytes, zero means no limit
+	MaxAttachmentSize int64
+	// CompressAttachments enables gzip compression of text attachments
+	CompressAttachments bool
+
+	mu       sync.Mutex
+	launches []*Launch
+
+	detect       bool
+	detectOnce   sync.Once
+	capabilities *Capabilities
 }
 
 // History defines activity history
@@ -61,37 +87,22 @@ type ActivityContent struct {
 	UserRef          string             `json:"userRef"`
 }
 
-// Page defines page info for activity
-type ActivityPage struct {
+// PageInfo defines page info of list responses
+type PageInfo struct {
 	Number        int `json:"number"`
 	Size          int `json:"size"`
 	TotalElements int `json:"totalElements"`
 	TotalPages    int `json:"totalPages"`
 }
 
-// Activity defines users activity on the project
-type Activity struct {
-	Content []*ActivityContent `json:"content"`
-	Page    *ActivityPage      `json:"page"`
-}
+// ActivityPage defines page info of list responses, kept for compatibility with PageInfo
+type ActivityPage = PageInfo
 
-// Widget defines widget info
-type Widget struct {
-	Id       string `json:"widgetId"`
-	Size     []int  `json:"widgetSize"`
-	Position []int  `json:"widgetPosition"`
-}
-
-// Dashboard defines dashoard info
-type Dashboard []struct {
-	Owner   string    `json:"owner"`
-	Share   bool      `json:"share"`
-	Id      string    `json:"id"`
-	Name    string    `json:"name"`
-	Widgets []*Widget `json:"widgets"`
-}
+// Activity defines users activity on the project
+type Activity = Page[*ActivityContent]
 
-// NewClient creates new client for ReportPortal endpoint
+// NewClient creates new client for ReportPortal endpoint. Server is detected before the first request
+// unless ServerVersion is set
 func NewClient(endpoint, project, token string, apiVersion int) *Client {
 	endpoint = strings.TrimSuffix(endpoint, "/")
 
@@ -114,59 +125,128 @@ func NewClient(endpoint, project, token string, apiVersion int) *Client {
 		Endpoint: esb.String(),
 		Project:  project,
 		Token:    token,
+		detect:   true,
 	}
 }
 
 // CheckConnect checks connection to ReportPortal
 func (c *Client) CheckConnect() error {
-	url := fmt.Sprintf("%s/user", c.Endpoint)
+	url := fmt.Sprintf("%s/user", c.endpoint())
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return errors.Wrapf(err, "can't create a new request for %s", url)
 	}
 
 	resp, err := doRequest(req, c.Token)
-	defer resp.Body.Close()
-
 	if err != nil {
 		return errors.Wrapf(err, "failed to execute GET request %s", req.URL)
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		return errors.Errorf("failed with status %s", resp.Status)
 	}
 	return nil
 }
 
-// GetDashboard gets all dashboard resources for project
-func (c *Client) GetDashboard() (*Dashboard, error) {
-	url := fmt.Sprintf("%s/%s/dashboard", c.Endpoint, c.Project)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return nil, errors.Wrapf(err, "can't create request for %s", url)
+// CloseAll finishes all started and not finished test items bottom-up and launches with specified status
+func (c *Client) CloseAll(status string) error {
+	c.mu.Lock()
+	launches := make([]*Launch, len(c.launches))
+	copy(launches, c.launches)
+	c.mu.Unlock()
+
+	var errs MultiError
+	for _, l := range launches {
+		if l.Status() != "" {
+			continue
+		}
+
+		l.mu.Lock()
+		items := make([]*TestItem, len(l.items))
+		copy(items, l.items)
+		l.mu.Unlock()
+
+		for _, ti := range items {
+			errs = append(errs, ti.closeAll(status)...)
+		}
+		if err := l.Finish(status); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to finish launch %s", l.Id))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
 	}
+	return nil
+}
 
-	req.Header.Set("Content-Type", "application/json")
+// HandleSignals closes all open test items and launches as interrupted when process receives
+// SIGINT or SIGTERM. Received signal is sent to returned channel after closing, so caller can exit.
+// Returned function stops handling, channel is closed when handling is over
+func (c *Client) HandleSignals() (closed <-chan os.Signal, stop func()) {
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	out := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 
-	resp, err := doRequest(req, c.Token)
-	defer resp.Body.Close()
+	go func() {
+		defer close(out)
+		select {
+		case sig := <-sigs:
+			signal.Stop(sigs)
+			c.CloseAll(StatusInterrupted)
+			out <- sig
+		case <-done:
+		}
+	}()
 
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to execute GET request for %s", url)
+	var once sync.Once
+	return out, func() {
+		once.Do(func() {
+			signal.Stop(sigs)
+			close(done)
+		})
 	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.Errorf("failed with status %s", resp.Status)
+}
+
+// RecoverAndClose closes all open test items and launches as interrupted on panic and panics again.
+// Must be deferred directly: defer c.RecoverAndClose()
+func (c *Client) RecoverAndClose() {
+	if r := recover(); r != nil {
+		c.CloseAll(StatusInterrupted)
+		panic(r)
 	}
+}
+
+// addLaunch registers started launch
+func (c *Client) addLaunch(l *Launch) {
+	c.mu.Lock()
+	c.launches = append(c.launches, l)
+	c.mu.Unlock()
+}
 
-	var d *Dashboard
-	if err := json.NewDecoder(resp.Body).Decode(&d); err != nil {
-		return nil, errors.Wrap(err, "failed to decode response for dashboard")
+// removeLaunch unregisters finished launch
+func (c *Client) removeLaunch(l *Launch) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, launch := range c.launches {
+		if launch == l {
+			c.launches = append(c.launches[:i], c.launches[i+1:]...)
+			return
+		}
 	}
-	return d, nil
 }
 
 // GetActivity gets all activity info for project
 func (c *Client) GetActivity() (*Activity, error) {
-	url := fmt.Sprintf("%s/%s/activity", c.Endpoint, c.Project)
+	return c.QueryActivity(nil)
+}
+
+// QueryActivity gets activity info for project matching query
+func (c *Client) QueryActivity(q *Query) (*Activity, error) {
+	url, err := listURL(fmt.Sprintf("%s/%s/activity", c.endpoint(), c.Project), q, nil)
+	if err != nil {
+		return nil, err
+	}
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, errors.Wrapf(err, "can't create GET request for %s", url)
@@ -175,11 +255,10 @@ func (c *Client) GetActivity() (*Activity, error) {
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := doRequest(req, c.Token)
-	defer resp.Body.Close()
-
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to execute GET request for %s", req.URL)
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		return nil, errors.Errorf("failed with status %s", resp.Status)
 	}
@@ -190,3 +269,89 @@ func (c *Client) GetActivity() (*Activity, error) {
 	}
 	return a, nil
 }
+
+// IterActivity iterates over activity info for project matching query
+func (c *Client) IterActivity(q *Query) *Iterator[*ActivityContent] {
+	return NewIterator(func(number int) (*Activity, error) {
+		return c.QueryActivity(q.withPage(number))
+	})
+}
+
+// LinkExternalIssues links tickets from external bug tracking system to all specified test items
+func (c *Client) LinkExternalIssues(itemIds []string, issues []*ExternalIssue) error {
+	url := fmt.Sprintf("%s/%s/item/issue/link", c.endpoint(), c.Project)
+
+	type issue struct {
+		TicketId   string `json:"ticketId"`
+		Url        string `json:"url"`
+		BtsProject string `json:"btsProject"`
+		BtsUrl     string `json:"btsUrl"`
+		SubmitDate int64  `json:"submitDate,omitempty"`
+	}
+	data := struct {
+		TestItemIds []string `json:"testItemIds"`
+		Issues      []*issue `json:"issues"`
+	}{TestItemIds: itemIds}
+	for _, i := range issues {
+		var submitDate int64
+		if !i.SubmitDate.IsZero() {
+			submitDate = toTimestamp(i.SubmitDate)
+		}
+		data.Issues = append(data.Issues, &issue{i.TicketId, i.Url, i.BtsProject, i.BtsUrl, submitDate})
+	}
+
+	b, err := json.Marshal(&data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal object %v", data)
+	}
+
+	r := bytes.NewReader(b)
+	req, err := http.NewRequest(http.MethodPut, url, r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create PUT request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute PUT request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+	return nil
+}
+
+// UnlinkExternalIssues unlinks tickets with specified ids from all specified test items
+func (c *Client) UnlinkExternalIssues(itemIds, ticketIds []string) error {
+	url := fmt.Sprintf("%s/%s/item/issue/unlink", c.endpoint(), c.Project)
+	data := struct {
+		TestItemIds []string `json:"testItemIds"`
+		TicketIds   []string `json:"ticketIds"`
+	}{itemIds, ticketIds}
+
+	b, err := json.Marshal(&data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal object %v", data)
+	}
+
+	r := bytes.NewReader(b)
+	req, err := http.NewRequest(http.MethodPut, url, r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create PUT request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute PUT request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+	return nil
+}
@@ -1,8 +1,10 @@
 package rp
 
 import (
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 	"time"
 
@@ -63,63 +65,16 @@ func TestCheckConnect(t *testing.T) {
 		err := c.CheckConnect()
 		assert.EqualError(t, err, "failed with status 500 Internal Server Error")
 	})
-}
 
-func TestDashboard(t *testing.T) {
-	t.Run("Successful result", func(t *testing.T) {
-		okResponse := `[{"owner":"user","share": true,"id":"id123","name":"main","widgets":[{"widgetId":"wid123", "widgetSize":[12,7],"widgetPosition":[0,0]}]}]`
-		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			assert.Equal(t, "/test_project/dashboard", r.URL.Path)
-			assert.Equal(t, "GET", r.Method)
-			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
-
-			w.Write([]byte(okResponse))
-		})
-		s := httptest.NewServer(h)
-		defer s.Close()
+	t.Run("Transport error", func(t *testing.T) {
+		s := httptest.NewServer(http.NotFoundHandler())
+		s.Close()
 
 		c := &Client{
 			Endpoint: s.URL,
-			Project:  "test_project",
 		}
-
-		expected := &Dashboard{
-			{
-				Owner: "user",
-				Share: true,
-				Id:    "id123",
-				Name:  "main",
-				Widgets: []*Widget{
-					{
-						Id:       "wid123",
-						Size:     []int{12, 7},
-						Position: []int{0, 0},
-					},
-				},
-			},
-		}
-
-		d, err := c.GetDashboard()
-		assert.NoError(t, err)
-		assert.NotNil(t, d)
-
-		assert.Equal(t, expected, d)
-	})
-
-	t.Run("Wrong status code", func(t *testing.T) {
-		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusInternalServerError)
-		})
-		s := httptest.NewServer(h)
-		defer s.Close()
-
-		c := &Client{
-			Endpoint: s.URL,
-		}
-
-		d, err := c.GetDashboard()
-		assert.Nil(t, d)
-		assert.EqualError(t, err, "failed with status 500 Internal Server Error")
+		err := c.CheckConnect()
+		assert.Error(t, err)
 	})
 }
 
@@ -213,8 +168,239 @@ func TestActivity(t *testing.T) {
 			Endpoint: s.URL,
 		}
 
+		d, err := c.QueryActivity(nil)
+		assert.Nil(t, d)
+		assert.EqualError(t, err, "failed with status 500 Internal Server Error")
+	})
+
+	t.Run("Transport error", func(t *testing.T) {
+		s := httptest.NewServer(http.NotFoundHandler())
+		s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+		}
+
 		d, err := c.GetActivity()
 		assert.Nil(t, d)
+		assert.Error(t, err)
+	})
+}
+
+func TestLinkExternalIssues(t *testing.T) {
+	t.Run("Successful link", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/test_project/item/issue/link", r.URL.Path)
+			assert.Equal(t, "PUT", r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			d, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, `{"testItemIds":["id1","id2"],"issues":[{"ticketId":"BUG-1","url":"https://jira/BUG-1","btsProject":"BUG","btsUrl":"https://jira","submitDate":1546300800000}]}`, string(d))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		}
+
+		err := c.LinkExternalIssues([]string{"id1", "id2"}, []*ExternalIssue{
+			{
+				TicketId:   "BUG-1",
+				Url:        "https://jira/BUG-1",
+				BtsProject: "BUG",
+				BtsUrl:     "https://jira",
+				SubmitDate: time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+			},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+		}
+
+		err := c.LinkExternalIssues(nil, nil)
 		assert.EqualError(t, err, "failed with status 500 Internal Server Error")
 	})
 }
+
+func TestUnlinkExternalIssues(t *testing.T) {
+	t.Run("Successful unlink", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/test_project/item/issue/unlink", r.URL.Path)
+			assert.Equal(t, "PUT", r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			d, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, `{"testItemIds":["id1"],"ticketIds":["BUG-1"]}`, string(d))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		}
+
+		err := c.UnlinkExternalIssues([]string{"id1"}, []string{"BUG-1"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+		}
+
+		err := c.UnlinkExternalIssues(nil, nil)
+		assert.EqualError(t, err, "failed with status 500 Internal Server Error")
+	})
+}
+
+func TestCloseAll(t *testing.T) {
+	newTree := func(s *httptest.Server) *Client {
+		c := &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		}
+		l := &Launch{Id: "launch1", client: c}
+		root := &TestItem{Id: "root", launch: l, client: c}
+		root.addChild(&TestItem{Id: "passed", launch: l, client: c, status: StatusPassed})
+		root.addChild(&TestItem{Id: "open", launch: l, client: c})
+		l.addItem(root)
+		c.addLaunch(l)
+		c.addLaunch(&Launch{Id: "finished", client: c, status: StatusPassed})
+		return c
+	}
+
+	t.Run("Successful close", func(t *testing.T) {
+		var closed []string
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "PUT", r.Method)
+
+			d, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Contains(t, string(d), `"status":"INTERRUPTED"`)
+			closed = append(closed, r.URL.Path)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		err := newTree(s).CloseAll(StatusInterrupted)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"/test_project/item/open",
+			"/test_project/item/root",
+			"/test_project/launch/launch1/finish",
+		}, closed)
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		err := newTree(s).CloseAll(StatusInterrupted)
+		assert.EqualError(t, err, "failed to finish test item open: failed with status 500 Internal Server Error; "+
+			"failed to finish test item root: failed with status 500 Internal Server Error; "+
+			"failed to finish launch launch1: failed with status 500 Internal Server Error")
+	})
+
+	t.Run("Server unreachable", func(t *testing.T) {
+		s := httptest.NewServer(http.NotFoundHandler())
+		c := newTree(s)
+		s.Close()
+
+		var err error
+		assert.NotPanics(t, func() { err = c.CloseAll(StatusInterrupted) })
+		if assert.IsType(t, MultiError{}, err) {
+			errs := err.(MultiError)
+			assert.Len(t, errs, 3)
+			assert.Contains(t, errs[0].Error(), "failed to finish test item open: failed to execute PUT request")
+			assert.Contains(t, errs[2].Error(), "failed to finish launch launch1: failed to execute PUT request")
+		}
+	})
+}
+
+func TestRecoverAndClose(t *testing.T) {
+	var closed []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		closed = append(closed, r.URL.Path)
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := &Client{
+		Endpoint: s.URL,
+		Project:  "test_project",
+	}
+	c.addLaunch(&Launch{Id: "launch1", client: c})
+
+	assert.PanicsWithValue(t, "boom", func() {
+		defer c.RecoverAndClose()
+		panic("boom")
+	})
+	assert.Equal(t, []string{"/test_project/launch/launch1/finish"}, closed)
+}
+
+func TestHandleSignals(t *testing.T) {
+	t.Run("Signal", func(t *testing.T) {
+		var closed []string
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			closed = append(closed, r.URL.Path)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		}
+		c.addLaunch(&Launch{Id: "launch1", client: c})
+
+		signals, stop := c.HandleSignals()
+		defer stop()
+
+		p, err := os.FindProcess(os.Getpid())
+		assert.NoError(t, err)
+		if err := p.Signal(os.Interrupt); err != nil {
+			t.Skipf("can't send signal: %v", err)
+		}
+
+		select {
+		case sig := <-signals:
+			assert.Equal(t, os.Interrupt, sig)
+		case <-time.After(time.Second):
+			t.Fatal("signal wasn't handled")
+		}
+		assert.Equal(t, []string{"/test_project/launch/launch1/finish"}, closed)
+		_, ok := <-signals
+		assert.False(t, ok)
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		signals, stop := (&Client{}).HandleSignals()
+		stop()
+		stop()
+		_, ok := <-signals
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,285 @@
+package rp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Widget defines widget info
+type Widget struct {
+	Id       string `json:"widgetId"`
+	Size     []int  `json:"widgetSize"`
+	Position []int  `json:"widgetPosition"`
+}
+
+// DashboardResource defines dashboard info
+type DashboardResource struct {
+	Owner       string    `json:"owner"`
+	Share       bool      `json:"share"`
+	Id          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Widgets     []*Widget `json:"widgets"`
+}
+
+// Dashboard defines dashboards of project
+type Dashboard []*DashboardResource
+
+// GetDashboard gets all dashboard resources for project
+func (c *Client) GetDashboard() (*Dashboard, error) {
+	url := fmt.Sprintf("%s/%s/dashboard", c.endpoint(), c.Project)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't create request for %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute GET request for %s", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	var d *Dashboard
+	if err := json.NewDecoder(resp.Body).Decode(&d); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response for dashboard")
+	}
+	return d, nil
+}
+
+// DashboardPage defines page of dashboards
+type DashboardPage = Page[*DashboardResource]
+
+// QueryDashboards gets page of project dashboards matching query. Unpaged list
+// returned by servers before v5 is treated as the only page
+func (c *Client) QueryDashboards(q *Query) (*DashboardPage, error) {
+	url, err := listURL(fmt.Sprintf("%s/%s/dashboard", c.endpoint(), c.Project), q, nil)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't create GET request for %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute GET request for %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response for dashboards")
+	}
+	dp := &DashboardPage{}
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		err = json.Unmarshal(raw, &dp.Content)
+	} else {
+		err = json.Unmarshal(raw, dp)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode response for dashboards")
+	}
+	return dp, nil
+}
+
+// IterDashboards iterates over project dashboards matching query
+func (c *Client) IterDashboards(q *Query) *Iterator[*DashboardResource] {
+	return NewIterator(func(number int) (*DashboardPage, error) {
+		return c.QueryDashboards(q.withPage(number))
+	})
+}
+
+// GetDashboardById gets dashboard with specified id
+func (c *Client) GetDashboardById(id string) (*DashboardResource, error) {
+	url := fmt.Sprintf("%s/%s/dashboard/%s", c.endpoint(), c.Project, id)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't create request for %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute GET request for %s", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	var d *DashboardResource
+	if err := json.NewDecoder(resp.Body).Decode(&d); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response for dashboard")
+	}
+	return d, nil
+}
+
+// CreateDashboard creates new dashboard
+func (c *Client) CreateDashboard(name, description string, share bool) (*DashboardResource, error) {
+	url := fmt.Sprintf("%s/%s/dashboard", c.endpoint(), c.Project)
+	data := struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Share       bool   `json:"share"`
+	}{name, description, share}
+
+	b, err := json.Marshal(&data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal object %v", data)
+	}
+
+	r := bytes.NewReader(b)
+	req, err := http.NewRequest(http.MethodPost, url, r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create POST request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute POST request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return nil, errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	v := struct {
+		Id string `json:"id"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode response from %s", req.URL)
+	}
+	return &DashboardResource{
+		Share:       share,
+		Id:          v.Id,
+		Name:        name,
+		Description: description,
+	}, nil
+}
+
+// UpdateDashboard updates name, description and sharing of dashboard
+func (c *Client) UpdateDashboard(d *DashboardResource) error {
+	data := struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Share       bool   `json:"share"`
+	}{d.Name, d.Description, d.Share}
+	return c.updateDashboard(d.Id, data)
+}
+
+// ShareDashboard shares dashboard with all project members
+func (c *Client) ShareDashboard(id string) error {
+	return c.updateDashboard(id, map[string]bool{"share": true})
+}
+
+// UnshareDashboard makes dashboard visible only to its owner
+func (c *Client) UnshareDashboard(id string) error {
+	return c.updateDashboard(id, map[string]bool{"share": false})
+}
+
+// DeleteDashboard deletes dashboard with specified id
+func (c *Client) DeleteDashboard(id string) error {
+	url := fmt.Sprintf("%s/%s/dashboard/%s", c.endpoint(), c.Project, id)
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create DELETE request for %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute DELETE request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+	return nil
+}
+
+// AddWidget adds existing widget with its size and position to dashboard
+func (c *Client) AddWidget(dashboardId string, w *Widget) error {
+	return c.updateDashboard(dashboardId, map[string]*Widget{"addWidget": w})
+}
+
+// MoveWidget moves widget of dashboard to specified position
+func (c *Client) MoveWidget(dashboardId, widgetId string, x, y int) error {
+	return c.updateWidget(dashboardId, widgetId, func(w *Widget) {
+		w.Position = []int{x, y}
+	})
+}
+
+// ResizeWidget changes size of widget on dashboard
+func (c *Client) ResizeWidget(dashboardId, widgetId string, width, height int) error {
+	return c.updateWidget(dashboardId, widgetId, func(w *Widget) {
+		w.Size = []int{width, height}
+	})
+}
+
+// RemoveWidget removes widget from dashboard
+func (c *Client) RemoveWidget(dashboardId, widgetId string) error {
+	return c.updateDashboard(dashboardId, map[string]string{"deleteWidget": widgetId})
+}
+
+// updateWidget gets widget of dashboard, applies update to it and saves it
+func (c *Client) updateWidget(dashboardId, widgetId string, update func(w *Widget)) error {
+	d, err := c.GetDashboardById(dashboardId)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get dashboard %s", dashboardId)
+	}
+
+	for _, w := range d.Widgets {
+		if w.Id == widgetId {
+			update(w)
+			return c.updateDashboard(dashboardId, map[string][]*Widget{"updateWidgets": {w}})
+		}
+	}
+	return errors.Errorf("widget %s not found on dashboard %s", widgetId, dashboardId)
+}
+
+// updateDashboard sends update request with specified data for dashboard
+func (c *Client) updateDashboard(id string, data interface{}) error {
+	url := fmt.Sprintf("%s/%s/dashboard/%s", c.endpoint(), c.Project, id)
+
+	b, err := json.Marshal(&data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal object %v", data)
+	}
+
+	r := bytes.NewReader(b)
+	req, err := http.NewRequest(http.MethodPut, url, r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create PUT request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute PUT request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,358 @@
+package rp
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDashboard(t *testing.T) {
+	t.Run("Successful result", func(t *testing.T) {
+		okResponse := `[{"owner":"user","share": true,"id":"id123","name":"main","widgets":[{"widgetId":"wid123", "widgetSize":[12,7],"widgetPosition":[0,0]}]}]`
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/test_project/dashboard", r.URL.Path)
+			assert.Equal(t, "GET", r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			w.Write([]byte(okResponse))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		}
+
+		expected := &Dashboard{
+			{
+				Owner: "user",
+				Share: true,
+				Id:    "id123",
+				Name:  "main",
+				Widgets: []*Widget{
+					{
+						Id:       "wid123",
+						Size:     []int{12, 7},
+						Position: []int{0, 0},
+					},
+				},
+			},
+		}
+
+		d, err := c.GetDashboard()
+		assert.NoError(t, err)
+		assert.NotNil(t, d)
+
+		assert.Equal(t, expected, d)
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+		}
+
+		d, err := c.GetDashboard()
+		assert.Nil(t, d)
+		assert.EqualError(t, err, "failed with status 500 Internal Server Error")
+	})
+
+	t.Run("Transport error", func(t *testing.T) {
+		s := httptest.NewServer(http.NotFoundHandler())
+		s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+		}
+
+		d, err := c.GetDashboard()
+		assert.Nil(t, d)
+		assert.Error(t, err)
+	})
+}
+
+func TestIterDashboards(t *testing.T) {
+	t.Run("Paged response", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/test_project/dashboard", r.URL.Path)
+			assert.Equal(t, "1", r.URL.Query().Get("page.size"))
+			switch r.URL.Query().Get("page.page") {
+			case "1":
+				w.Write([]byte(`{"content":[{"id":"d1","name":"main"}],"page":{"number":1,"size":1,"totalElements":2,"totalPages":2}}`))
+			case "2":
+				w.Write([]byte(`{"content":[{"id":"d2","name":"nightly"}],"page":{"number":2,"size":1,"totalElements":2,"totalPages":2}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{Endpoint: s.URL, Project: "test_project"}
+		dashboards, err := Collect(c.IterDashboards(NewQuery().Size(1)), 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []*DashboardResource{{Id: "d1", Name: "main"}, {Id: "d2", Name: "nightly"}}, dashboards)
+	})
+
+	t.Run("Unpaged response", func(t *testing.T) {
+		var requests int
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Write([]byte(`[{"id":"d1","name":"main"}]`))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{Endpoint: s.URL, Project: "test_project"}
+		dashboards, err := Collect(c.IterDashboards(nil), 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []*DashboardResource{{Id: "d1", Name: "main"}}, dashboards)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{Endpoint: s.URL, Project: "test_project"}
+		dp, err := c.QueryDashboards(nil)
+		assert.Nil(t, dp)
+		assert.EqualError(t, err, "failed with status 500 Internal Server Error")
+	})
+}
+
+func TestGetDashboardById(t *testing.T) {
+	t.Run("Successful result", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/test_project/dashboard/id123", r.URL.Path)
+			assert.Equal(t, "GET", r.Method)
+
+			w.Write([]byte(`{"owner":"user","share":false,"id":"id123","name":"main","description":"team","widgets":[]}`))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		}
+
+		d, err := c.GetDashboardById("id123")
+		assert.NoError(t, err)
+		assert.Equal(t, &DashboardResource{
+			Owner:       "user",
+			Id:          "id123",
+			Name:        "main",
+			Description: "team",
+			Widgets:     []*Widget{},
+		}, d)
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+		}
+
+		d, err := c.GetDashboardById("id123")
+		assert.Nil(t, d)
+		assert.EqualError(t, err, "failed with status 404 Not Found")
+	})
+}
+
+func TestCreateDashboard(t *testing.T) {
+	t.Run("Successful create", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/test_project/dashboard", r.URL.Path)
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			d, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, `{"name":"main","description":"team","share":true}`, string(d))
+
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": "id123"}`))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		}
+
+		d, err := c.CreateDashboard("main", "team", true)
+		assert.NoError(t, err)
+		assert.Equal(t, &DashboardResource{Id: "id123", Name: "main", Description: "team", Share: true}, d)
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+		}
+
+		d, err := c.CreateDashboard("main", "", false)
+		assert.Nil(t, d)
+		assert.EqualError(t, err, "failed with status 409 Conflict")
+	})
+}
+
+func TestUpdateDashboard(t *testing.T) {
+	var updates = []struct {
+		name     string
+		update   func(c *Client) error
+		expected string
+	}{
+		{
+			"Update",
+			func(c *Client) error {
+				return c.UpdateDashboard(&DashboardResource{Id: "id123", Name: "new", Description: "new team"})
+			},
+			`{"name":"new","description":"new team","share":false}`,
+		},
+		{
+			"Share",
+			func(c *Client) error { return c.ShareDashboard("id123") },
+			`{"share":true}`,
+		},
+		{
+			"Unshare",
+			func(c *Client) error { return c.UnshareDashboard("id123") },
+			`{"share":false}`,
+		},
+		{
+			"Add widget",
+			func(c *Client) error {
+				return c.AddWidget("id123", &Widget{Id: "wid1", Size: []int{6, 5}, Position: []int{0, 0}})
+			},
+			`{"addWidget":{"widgetId":"wid1","widgetSize":[6,5],"widgetPosition":[0,0]}}`,
+		},
+		{
+			"Remove widget",
+			func(c *Client) error { return c.RemoveWidget("id123", "wid1") },
+			`{"deleteWidget":"wid1"}`,
+		},
+	}
+
+	for _, tt := range updates {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/test_project/dashboard/id123", r.URL.Path)
+				assert.Equal(t, "PUT", r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+				d, err := ioutil.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, string(d))
+			})
+			s := httptest.NewServer(h)
+			defer s.Close()
+
+			c := &Client{
+				Endpoint: s.URL,
+				Project:  "test_project",
+			}
+			assert.NoError(t, tt.update(c))
+		})
+	}
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+		}
+
+		err := c.ShareDashboard("id123")
+		assert.EqualError(t, err, "failed with status 500 Internal Server Error")
+	})
+}
+
+func TestMoveAndResizeWidget(t *testing.T) {
+	var updated string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/test_project/dashboard/id123", r.URL.Path)
+		if r.Method == "GET" {
+			w.Write([]byte(`{"id":"id123","widgets":[{"widgetId":"wid1","widgetSize":[6,5],"widgetPosition":[0,0]}]}`))
+			return
+		}
+		d, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		updated = string(d)
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := &Client{
+		Endpoint: s.URL,
+		Project:  "test_project",
+	}
+
+	assert.NoError(t, c.MoveWidget("id123", "wid1", 6, 0))
+	assert.Equal(t, `{"updateWidgets":[{"widgetId":"wid1","widgetSize":[6,5],"widgetPosition":[6,0]}]}`, updated)
+
+	assert.NoError(t, c.ResizeWidget("id123", "wid1", 12, 7))
+	assert.Equal(t, `{"updateWidgets":[{"widgetId":"wid1","widgetSize":[12,7],"widgetPosition":[0,0]}]}`, updated)
+
+	err := c.ResizeWidget("id123", "missing", 12, 7)
+	assert.EqualError(t, err, "widget missing not found on dashboard id123")
+}
+
+func TestDeleteDashboard(t *testing.T) {
+	t.Run("Successful delete", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/test_project/dashboard/id123", r.URL.Path)
+			assert.Equal(t, "DELETE", r.Method)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		}
+		assert.NoError(t, c.DeleteDashboard("id123"))
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+		}
+		err := c.DeleteDashboard("id123")
+		assert.EqualError(t, err, "failed with status 500 Internal Server Error")
+	})
+}
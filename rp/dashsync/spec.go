@@ -0,0 +1,178 @@
+// Package dashsync reconciles dashboards, widgets and filters of ReportPortal project
+// with their declarative description in YAML or JSON
+package dashsync
+
+import (
+	"io/ioutil"
+
+	"github.com/igorexec/client-go/rp"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec defines desired filters and dashboards of project
+type Spec struct {
+	Filters    []*FilterSpec    `yaml:"filters"`
+	Dashboards []*DashboardSpec `yaml:"dashboards"`
+}
+
+// FilterSpec defines desired saved filter
+type FilterSpec struct {
+	Name        string           `yaml:"name"`
+	Description string           `yaml:"description"`
+	Type        string           `yaml:"type"`
+	Share       bool             `yaml:"share"`
+	Conditions  []*ConditionSpec `yaml:"conditions"`
+	Orders      []*OrderSpec     `yaml:"orders"`
+}
+
+// ConditionSpec defines condition of saved filter
+type ConditionSpec struct {
+	Field     string `yaml:"field"`
+	Condition string `yaml:"condition"`
+	Value     string `yaml:"value"`
+}
+
+// OrderSpec defines sort order of saved filter
+type OrderSpec struct {
+	Column string `yaml:"column"`
+	Asc    bool   `yaml:"asc"`
+}
+
+// DashboardSpec defines desired dashboard with its widgets
+type DashboardSpec struct {
+	Name        string        `yaml:"name"`
+	Description string        `yaml:"description"`
+	Share       bool          `yaml:"share"`
+	Widgets     []*WidgetSpec `yaml:"widgets"`
+}
+
+// WidgetSpec defines desired widget placed on dashboard,
+// filters are referenced by names
+type WidgetSpec struct {
+	Name          string                 `yaml:"name"`
+	Description   string                 `yaml:"description"`
+	Type          string                 `yaml:"type"`
+	Share         bool                   `yaml:"share"`
+	Filters       []string               `yaml:"filters"`
+	ContentFields []string               `yaml:"contentFields"`
+	ItemsCount    int                    `yaml:"itemsCount"`
+	Options       map[string]interface{} `yaml:"options"`
+	Size          []int                  `yaml:"size"`
+	Position      []int                  `yaml:"position"`
+}
+
+// Parse parses spec from YAML or JSON data
+func Parse(data []byte) (*Spec, error) {
+	var s *Spec
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, errors.Wrap(err, "failed to parse spec")
+	}
+	if s == nil {
+		s = &Spec{}
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load reads and parses spec from YAML or JSON file
+func Load(path string) (*Spec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read spec %s", path)
+	}
+	return Parse(data)
+}
+
+// validate checks that names are set and unique and widgets reference known filters
+func (s *Spec) validate() error {
+	filters := make(map[string]bool)
+	for _, f := range s.Filters {
+		if f.Name == "" {
+			return errors.New("filter name is empty")
+		}
+		if filters[f.Name] {
+			return errors.Errorf("filter %q is defined twice", f.Name)
+		}
+		filters[f.Name] = true
+	}
+
+	dashboards := make(map[string]bool)
+	for _, d := range s.Dashboards {
+		if d.Name == "" {
+			return errors.New("dashboard name is empty")
+		}
+		if dashboards[d.Name] {
+			return errors.Errorf("dashboard %q is defined twice", d.Name)
+		}
+		dashboards[d.Name] = true
+
+		widgets := make(map[string]bool)
+		for _, w := range d.Widgets {
+			if w.Name == "" {
+				return errors.Errorf("widget name is empty on dashboard %q", d.Name)
+			}
+			if widgets[w.Name] {
+				return errors.Errorf("widget %q is defined twice on dashboard %q", w.Name, d.Name)
+			}
+			widgets[w.Name] = true
+			if len(w.Size) != 0 && len(w.Size) != 2 {
+				return errors.Errorf("size of widget %q must be [width, height]", w.Name)
+			}
+			if len(w.Position) != 0 && len(w.Position) != 2 {
+				return errors.Errorf("position of widget %q must be [x, y]", w.Name)
+			}
+			for _, name := range w.Filters {
+				if !filters[name] {
+					return errors.Errorf("widget %q references unknown filter %q", w.Name, name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// userFilter converts spec to filter resource
+func (f *FilterSpec) userFilter() *rp.UserFilter {
+	uf := &rp.UserFilter{
+		Name:        f.Name,
+		Description: f.Description,
+		Type:        f.Type,
+		Share:       f.Share,
+		Conditions:  []*rp.FilterCondition{},
+		Orders:      []*rp.FilterOrder{},
+	}
+	for _, c := range f.Conditions {
+		uf.Conditions = append(uf.Conditions, &rp.FilterCondition{
+			FilteringField: c.Field,
+			Condition:      c.Condition,
+			Value:          c.Value,
+		})
+	}
+	for _, o := range f.Orders {
+		uf.Orders = append(uf.Orders, &rp.FilterOrder{SortingColumn: o.Column, IsAsc: o.Asc})
+	}
+	return uf
+}
+
+// widgetResource converts spec to widget resource with specified filter ids
+func (w *WidgetSpec) widgetResource(filterIds []string) *rp.WidgetResource {
+	contentFields := w.ContentFields
+	if contentFields == nil {
+		contentFields = []string{}
+	}
+	return &rp.WidgetResource{
+		Name:        w.Name,
+		Description: w.Description,
+		WidgetType:  w.Type,
+		Share:       w.Share,
+		FilterIds:   filterIds,
+		ContentParameters: &rp.WidgetContentParameters{
+			ContentFields: contentFields,
+			ItemsCount:    w.ItemsCount,
+			WidgetOptions: w.Options,
+		},
+	}
+}
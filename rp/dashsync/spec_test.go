@@ -0,0 +1,64 @@
+package dashsync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("YAML", func(t *testing.T) {
+		s, err := Parse([]byte(`
+filters:
+  - name: failed
+    type: launch
+    conditions:
+      - {field: status, condition: in, value: "FAILED,INTERRUPTED"}
+    orders:
+      - {column: startTime}
+dashboards:
+  - name: Nightly
+    share: true
+    widgets:
+      - name: failures
+        type: statisticTrend
+        filters: [failed]
+        itemsCount: 50
+        options: {timeline: launch}
+        size: [6, 4]
+        position: [0, 0]
+`))
+		assert.NoError(t, err)
+		assert.Len(t, s.Filters, 1)
+		assert.Equal(t, &ConditionSpec{"status", "in", "FAILED,INTERRUPTED"}, s.Filters[0].Conditions[0])
+		assert.Equal(t, "Nightly", s.Dashboards[0].Name)
+		assert.Equal(t, map[string]interface{}{"timeline": "launch"}, s.Dashboards[0].Widgets[0].Options)
+		assert.Equal(t, []int{6, 4}, s.Dashboards[0].Widgets[0].Size)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		s, err := Parse([]byte(`{"dashboards": [{"name": "Nightly", "widgets": [{"name": "failures", "type": "statisticTrend"}]}]}`))
+		assert.NoError(t, err)
+		assert.Equal(t, "failures", s.Dashboards[0].Widgets[0].Name)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		s, err := Parse(nil)
+		assert.NoError(t, err)
+		assert.Empty(t, s.Dashboards)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		cases := map[string]string{
+			`filters: [{name: a}, {name: a}]`:                                    `filter "a" is defined twice`,
+			`dashboards: [{description: d}]`:                                     "dashboard name is empty",
+			`dashboards: [{name: d, widgets: [{name: w, filters: [f]}]}]`:        `widget "w" references unknown filter "f"`,
+			`dashboards: [{name: d, widgets: [{name: w}, {name: w}]}]`:           `widget "w" is defined twice on dashboard "d"`,
+			`dashboards: [{name: d, widgets: [{name: w, position: [1, 2, 3]}]}]`: `position of widget "w" must be [x, y]`,
+		}
+		for data, msg := range cases {
+			_, err := Parse([]byte(data))
+			assert.EqualError(t, err, msg, data)
+		}
+	})
+}
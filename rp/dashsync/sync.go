@@ -0,0 +1,434 @@
+package dashsync
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/igorexec/client-go/rp"
+	"github.com/pkg/errors"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+
+	KindFilter    = "filter"
+	KindDashboard = "dashboard"
+	KindWidget    = "widget"
+)
+
+// Options defines options of sync
+type Options struct {
+	// Prune deletes filters, dashboards and widgets missing in spec which are owned by user of client
+	Prune bool
+	// DryRun only calculates changes without applying them
+	DryRun bool
+}
+
+// Change defines change of project object. Widgets are named as dashboard/widget
+type Change struct {
+	Action string
+	Kind   string
+	Name   string
+	Fields []string
+}
+
+// String returns change in diff notation
+func (ch *Change) String() string {
+	sign := "~"
+	switch ch.Action {
+	case ActionCreate:
+		sign = "+"
+	case ActionDelete:
+		sign = "-"
+	}
+	s := fmt.Sprintf("%s %s %q", sign, ch.Kind, ch.Name)
+	if len(ch.Fields) > 0 {
+		s += " (" + strings.Join(ch.Fields, ", ") + ")"
+	}
+	return s
+}
+
+// syncer holds state of single sync
+type syncer struct {
+	client      *rp.Client
+	opts        Options
+	filterIds   map[string]string
+	filterNames map[string]string
+	owner       string
+	changes     []*Change
+}
+
+// Sync reconciles project of client with spec: creates missing objects, updates changed ones
+// and deletes extra ones owned by user of client when Prune is set. Returns changes applied or,
+// with DryRun, changes which would be applied
+func Sync(c *rp.Client, s *Spec, opts Options) ([]*Change, error) {
+	sc := &syncer{
+		client:      c,
+		opts:        opts,
+		filterIds:   make(map[string]string),
+		filterNames: make(map[string]string),
+	}
+
+	if opts.Prune {
+		u, err := c.GetCurrentUser()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get current user")
+		}
+		sc.owner = u.Login
+	}
+
+	extra, err := sc.syncFilters(s.Filters)
+	if err != nil {
+		return sc.changes, err
+	}
+	if err := sc.syncDashboards(s.Dashboards); err != nil {
+		return sc.changes, err
+	}
+
+	// filters are pruned last as they can be used by pruned widgets
+	if opts.Prune {
+		for _, f := range extra {
+			if !opts.DryRun {
+				if err := c.DeleteFilter(f.Id); err != nil {
+					return sc.changes, errors.Wrapf(err, "failed to delete filter %q", f.Name)
+				}
+			}
+			sc.record(ActionDelete, KindFilter, f.Name, nil)
+		}
+	}
+	return sc.changes, nil
+}
+
+// syncFilters creates and updates filters of spec. Returns existing filters missing in spec
+func (sc *syncer) syncFilters(specs []*FilterSpec) ([]*rp.UserFilter, error) {
+	filters, err := rp.Collect(sc.client.IterFilters(nil), 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get filters")
+	}
+
+	existing := make(map[string]*rp.UserFilter)
+	for _, f := range filters {
+		existing[f.Name] = f
+		sc.filterNames[f.Id] = f.Name
+	}
+
+	for _, fs := range specs {
+		want := fs.userFilter()
+		cur, ok := existing[fs.Name]
+		if !ok {
+			var id string
+			if !sc.opts.DryRun {
+				if id, err = sc.client.CreateFilter(want); err != nil {
+					return nil, errors.Wrapf(err, "failed to create filter %q", fs.Name)
+				}
+			}
+			sc.filterIds[fs.Name] = id
+			sc.record(ActionCreate, KindFilter, fs.Name, nil)
+			continue
+		}
+		delete(existing, fs.Name)
+		sc.filterIds[fs.Name] = cur.Id
+
+		fields := diffFilter(cur, want)
+		if len(fields) == 0 {
+			continue
+		}
+		if !sc.opts.DryRun {
+			want.Id = cur.Id
+			if err := sc.client.UpdateFilter(want); err != nil {
+				return nil, errors.Wrapf(err, "failed to update filter %q", fs.Name)
+			}
+		}
+		sc.record(ActionUpdate, KindFilter, fs.Name, fields)
+	}
+
+	var extra []*rp.UserFilter
+	for _, f := range filters {
+		if _, ok := existing[f.Name]; ok && sc.owns(f.Owner) {
+			extra = append(extra, f)
+		}
+	}
+	return extra, nil
+}
+
+// syncDashboards creates, updates and prunes dashboards of spec with their widgets
+func (sc *syncer) syncDashboards(specs []*DashboardSpec) error {
+	d, err := sc.client.GetDashboard()
+	if err != nil {
+		return errors.Wrap(err, "failed to get dashboards")
+	}
+
+	existing := make(map[string]*rp.DashboardResource)
+	if d != nil {
+		for _, dr := range *d {
+			existing[dr.Name] = dr
+		}
+	}
+
+	for _, ds := range specs {
+		cur, ok := existing[ds.Name]
+		if !ok {
+			var id string
+			if !sc.opts.DryRun {
+				dr, err := sc.client.CreateDashboard(ds.Name, ds.Description, ds.Share)
+				if err != nil {
+					return errors.Wrapf(err, "failed to create dashboard %q", ds.Name)
+				}
+				id = dr.Id
+			}
+			sc.record(ActionCreate, KindDashboard, ds.Name, nil)
+			if err := sc.syncWidgets(ds, id, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		delete(existing, ds.Name)
+
+		var fields []string
+		if cur.Description != ds.Description {
+			fields = append(fields, "description")
+		}
+		if cur.Share != ds.Share {
+			fields = append(fields, "share")
+		}
+		if len(fields) > 0 {
+			if !sc.opts.DryRun {
+				err := sc.client.UpdateDashboard(&rp.DashboardResource{
+					Id:          cur.Id,
+					Name:        ds.Name,
+					Description: ds.Description,
+					Share:       ds.Share,
+				})
+				if err != nil {
+					return errors.Wrapf(err, "failed to update dashboard %q", ds.Name)
+				}
+			}
+			sc.record(ActionUpdate, KindDashboard, ds.Name, fields)
+		}
+
+		if err := sc.syncWidgets(ds, cur.Id, cur.Widgets); err != nil {
+			return err
+		}
+	}
+
+	if !sc.opts.Prune || d == nil {
+		return nil
+	}
+	for _, dr := range *d {
+		if _, ok := existing[dr.Name]; !ok || !sc.owns(dr.Owner) {
+			continue
+		}
+		if !sc.opts.DryRun {
+			if err := sc.client.DeleteDashboard(dr.Id); err != nil {
+				return errors.Wrapf(err, "failed to delete dashboard %q", dr.Name)
+			}
+		}
+		sc.record(ActionDelete, KindDashboard, dr.Name, nil)
+	}
+	return nil
+}
+
+// placedWidget defines widget with its placement on dashboard
+type placedWidget struct {
+	resource  *rp.WidgetResource
+	placement *rp.Widget
+}
+
+// syncWidgets creates, updates, places and prunes widgets of dashboard
+func (sc *syncer) syncWidgets(ds *DashboardSpec, dashboardId string, placed []*rp.Widget) error {
+	existing := make(map[string]*placedWidget)
+	var order []string
+	for _, p := range placed {
+		wc, err := sc.client.GetWidgetContent(p.Id)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get widget %s of dashboard %q", p.Id, ds.Name)
+		}
+		existing[wc.Name] = &placedWidget{&wc.WidgetResource, p}
+		order = append(order, wc.Name)
+	}
+
+	for _, ws := range ds.Widgets {
+		name := ds.Name + "/" + ws.Name
+		filterIds := make([]string, 0, len(ws.Filters))
+		for _, f := range ws.Filters {
+			filterIds = append(filterIds, sc.filterIds[f])
+		}
+		want := ws.widgetResource(filterIds)
+
+		cur, ok := existing[ws.Name]
+		if !ok {
+			if !sc.opts.DryRun {
+				id, err := sc.client.CreateWidget(want)
+				if err != nil {
+					return errors.Wrapf(err, "failed to create widget %q", name)
+				}
+				err = sc.client.AddWidget(dashboardId, &rp.Widget{Id: id, Size: ws.Size, Position: ws.Position})
+				if err != nil {
+					return errors.Wrapf(err, "failed to add widget %q", name)
+				}
+			}
+			sc.record(ActionCreate, KindWidget, name, nil)
+			continue
+		}
+		delete(existing, ws.Name)
+
+		fields := sc.diffWidget(cur.resource, want, ws.Filters)
+		if len(fields) > 0 && !sc.opts.DryRun {
+			want.Id = cur.resource.Id
+			if err := sc.client.UpdateWidget(want); err != nil {
+				return errors.Wrapf(err, "failed to update widget %q", name)
+			}
+		}
+		if len(ws.Size) == 2 && !equalInts(ws.Size, cur.placement.Size) {
+			if !sc.opts.DryRun {
+				if err := sc.client.ResizeWidget(dashboardId, cur.placement.Id, ws.Size[0], ws.Size[1]); err != nil {
+					return errors.Wrapf(err, "failed to resize widget %q", name)
+				}
+			}
+			fields = append(fields, "size")
+		}
+		if len(ws.Position) == 2 && !equalInts(ws.Position, cur.placement.Position) {
+			if !sc.opts.DryRun {
+				if err := sc.client.MoveWidget(dashboardId, cur.placement.Id, ws.Position[0], ws.Position[1]); err != nil {
+					return errors.Wrapf(err, "failed to move widget %q", name)
+				}
+			}
+			fields = append(fields, "position")
+		}
+		if len(fields) > 0 {
+			sc.record(ActionUpdate, KindWidget, name, fields)
+		}
+	}
+
+	if !sc.opts.Prune {
+		return nil
+	}
+	for _, n := range order {
+		pw, ok := existing[n]
+		if !ok || !sc.owns(pw.resource.Owner) {
+			continue
+		}
+		if !sc.opts.DryRun {
+			if err := sc.client.RemoveWidget(dashboardId, pw.placement.Id); err != nil {
+				return errors.Wrapf(err, "failed to remove widget %q", ds.Name+"/"+n)
+			}
+			if err := sc.client.DeleteWidget(pw.resource.Id); err != nil {
+				return errors.Wrapf(err, "failed to delete widget %q", ds.Name+"/"+n)
+			}
+		}
+		sc.record(ActionDelete, KindWidget, ds.Name+"/"+n, nil)
+	}
+	return nil
+}
+
+// diffWidget returns names of changed fields of widget
+func (sc *syncer) diffWidget(cur, want *rp.WidgetResource, filters []string) []string {
+	var fields []string
+	if cur.Description != want.Description {
+		fields = append(fields, "description")
+	}
+	if cur.WidgetType != want.WidgetType {
+		fields = append(fields, "type")
+	}
+	if cur.Share != want.Share {
+		fields = append(fields, "share")
+	}
+
+	var curFilters []string
+	if len(cur.AppliedFilters) > 0 {
+		for _, f := range cur.AppliedFilters {
+			curFilters = append(curFilters, f.Name)
+		}
+	} else {
+		for _, id := range cur.FilterIds {
+			curFilters = append(curFilters, sc.filterNames[id])
+		}
+	}
+	if !equalNames(curFilters, filters) {
+		fields = append(fields, "filters")
+	}
+
+	if !equalJSON(cur.ContentParameters, want.ContentParameters) {
+		fields = append(fields, "contentParameters")
+	}
+	return fields
+}
+
+// diffFilter returns names of changed fields of filter
+func diffFilter(cur, want *rp.UserFilter) []string {
+	var fields []string
+	if cur.Description != want.Description {
+		fields = append(fields, "description")
+	}
+	if cur.Type != want.Type {
+		fields = append(fields, "type")
+	}
+	if cur.Share != want.Share {
+		fields = append(fields, "share")
+	}
+	if (len(cur.Conditions) > 0 || len(want.Conditions) > 0) && !equalJSON(cur.Conditions, want.Conditions) {
+		fields = append(fields, "conditions")
+	}
+	if (len(cur.Orders) > 0 || len(want.Orders) > 0) && !equalJSON(cur.Orders, want.Orders) {
+		fields = append(fields, "orders")
+	}
+	return fields
+}
+
+// owns reports whether object with specified owner can be pruned
+func (sc *syncer) owns(owner string) bool {
+	return owner != "" && owner == sc.owner
+}
+
+// record adds change to result of sync
+func (sc *syncer) record(action, kind, name string, fields []string) {
+	sc.changes = append(sc.changes, &Change{action, kind, name, fields})
+}
+
+// equalJSON compares JSON representation of objects, so numbers decoded
+// from spec and from response are equal
+func equalJSON(a, b interface{}) bool {
+	ab, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(ab) == string(bb)
+}
+
+// equalInts compares int slices
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// equalNames compares names regardless of their order
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	as := append([]string(nil), a...)
+	bs := append([]string(nil), b...)
+	sort.Strings(as)
+	sort.Strings(bs)
+	for i := range as {
+		if as[i] != bs[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,170 @@
+package dashsync
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/igorexec/client-go/rp"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSpec = `
+filters:
+  - name: failed
+    type: launch
+    conditions:
+      - {field: status, condition: in, value: "FAILED,INTERRUPTED"}
+  - name: smoke
+    type: launch
+    conditions:
+      - {field: name, condition: cnt, value: smoke}
+dashboards:
+  - name: Nightly
+    description: nightly runs
+    widgets:
+      - name: failures
+        type: statisticTrend
+        filters: [failed]
+        itemsCount: 50
+        options: {timeline: launch}
+        size: [6, 4]
+        position: [0, 4]
+      - name: durations
+        type: launchesDurationChart
+        filters: [failed]
+  - name: Weekly
+    widgets:
+      - name: trend
+        type: statisticTrend
+        filters: [smoke]
+`
+
+// newTestServer starts fake ReportPortal with existing filters, dashboards and widgets.
+// Mutating requests are recorded to requests
+func newTestServer(requests *[]string) *httptest.Server {
+	get := map[string]string{
+		"/user": `{"userId":"me"}`,
+		"/p/filter": `{"content":[` +
+			`{"id":"fid1","name":"failed","type":"launch","conditions":[{"filteringField":"status","condition":"eq","value":"FAILED"}],"orders":[]},` +
+			`{"id":"fid2","name":"old","type":"launch","owner":"me","conditions":[],"orders":[]},` +
+			`{"id":"fid3","name":"foreign","type":"launch","owner":"other","conditions":[],"orders":[]}]}`,
+		"/p/dashboard": `[` +
+			`{"id":"did1","name":"Nightly","widgets":[{"widgetId":"wid1","widgetSize":[6,4],"widgetPosition":[0,0]},` +
+			`{"widgetId":"wid2","widgetSize":[2,2],"widgetPosition":[6,0]},{"widgetId":"wid3","widgetSize":[2,2],"widgetPosition":[8,0]}]},` +
+			`{"id":"did2","name":"Legacy","owner":"me","widgets":[]},` +
+			`{"id":"did3","name":"Foreign","owner":"other","widgets":[]}]`,
+		"/p/dashboard/did1": `{"id":"did1","name":"Nightly","widgets":[{"widgetId":"wid1","widgetSize":[6,4],"widgetPosition":[0,0]}]}`,
+		"/p/widget/wid1": `{"id":"wid1","name":"failures","widgetType":"statisticTrend","appliedFilters":[{"id":"fid1","name":"failed"}],` +
+			`"contentParameters":{"contentFields":[],"itemsCount":50,"widgetOptions":{"timeline":"launch"}},"content":{}}`,
+		"/p/widget/wid2": `{"id":"wid2","name":"stale","widgetType":"activityStream","owner":"me","contentParameters":{"contentFields":[],"itemsCount":10},"content":{}}`,
+		"/p/widget/wid3": `{"id":"wid3","name":"foreign","widgetType":"activityStream","owner":"other","contentParameters":{"contentFields":[],"itemsCount":10},"content":{}}`,
+	}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			body, ok := get[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(body))
+			return
+		}
+
+		*requests = append(*requests, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"new"}`))
+		}
+	})
+	return httptest.NewServer(h)
+}
+
+func TestSync(t *testing.T) {
+	s, err := Parse([]byte(testSpec))
+	assert.NoError(t, err)
+
+	t.Run("Prune", func(t *testing.T) {
+		var requests []string
+		srv := newTestServer(&requests)
+		defer srv.Close()
+
+		c := &rp.Client{Endpoint: srv.URL, Project: "p"}
+		changes, err := Sync(c, s, Options{Prune: true})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			`~ filter "failed" (conditions)`,
+			`+ filter "smoke"`,
+			`~ dashboard "Nightly" (description)`,
+			`~ widget "Nightly/failures" (position)`,
+			`+ widget "Nightly/durations"`,
+			`- widget "Nightly/stale"`,
+			`+ dashboard "Weekly"`,
+			`+ widget "Weekly/trend"`,
+			`- dashboard "Legacy"`,
+			`- filter "old"`,
+		}, changeStrings(changes))
+		assert.Equal(t, []string{
+			"PUT /p/filter/fid1",
+			"POST /p/filter",
+			"PUT /p/dashboard/did1",
+			"PUT /p/dashboard/did1",
+			"POST /p/widget",
+			"PUT /p/dashboard/did1",
+			"PUT /p/dashboard/did1",
+			"DELETE /p/widget/wid2",
+			"POST /p/dashboard",
+			"POST /p/widget",
+			"PUT /p/dashboard/new",
+			"DELETE /p/dashboard/did2",
+			"DELETE /p/filter/fid2",
+		}, requests)
+		assert.NotContains(t, requests, "DELETE /p/filter/fid3")
+		assert.NotContains(t, requests, "DELETE /p/dashboard/did3")
+		assert.NotContains(t, requests, "DELETE /p/widget/wid3")
+	})
+
+	t.Run("Without prune", func(t *testing.T) {
+		var requests []string
+		srv := newTestServer(&requests)
+		defer srv.Close()
+
+		c := &rp.Client{Endpoint: srv.URL, Project: "p"}
+		changes, err := Sync(c, s, Options{})
+		assert.NoError(t, err)
+		assert.Len(t, changes, 7)
+		assert.NotContains(t, requests, "DELETE /p/dashboard/did2")
+	})
+
+	t.Run("Dry run", func(t *testing.T) {
+		var requests []string
+		srv := newTestServer(&requests)
+		defer srv.Close()
+
+		c := &rp.Client{Endpoint: srv.URL, Project: "p"}
+		changes, err := Sync(c, s, Options{Prune: true, DryRun: true})
+		assert.NoError(t, err)
+		assert.Len(t, changes, 10)
+		assert.Empty(t, requests)
+	})
+
+	t.Run("Failed request", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+		srv := httptest.NewServer(h)
+		defer srv.Close()
+
+		c := &rp.Client{Endpoint: srv.URL, Project: "p"}
+		_, err := Sync(c, s, Options{})
+		assert.EqualError(t, err, "failed to get filters: failed with status 401 Unauthorized")
+	})
+}
+
+func changeStrings(changes []*Change) []string {
+	var s []string
+	for _, ch := range changes {
+		s = append(s, ch.String())
+	}
+	return s
+}
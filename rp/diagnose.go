@@ -0,0 +1,268 @@
+package rp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DiagnosticOK      = "OK"
+	DiagnosticWarning = "WARNING"
+	DiagnosticFailed  = "FAILED"
+	DiagnosticSkipped = "SKIPPED"
+
+	CheckReachability = "reachability"
+	CheckTLS          = "tls"
+	CheckAPIVersion   = "api version"
+	CheckToken        = "token"
+	CheckProject      = "project"
+
+	// expiryWarning is a period before expiry of token or certificate when warning is reported
+	expiryWarning = 7 * 24 * time.Hour
+)
+
+// DiagnosticCheck defines result of single diagnostic check
+type DiagnosticCheck struct {
+	Name    string
+	Status  string
+	Message string
+}
+
+// Diagnostics defines report of connection diagnostics
+type Diagnostics struct {
+	Endpoint       string
+	TLSVersion     string
+	CertExpiresAt  time.Time
+	APIVersion     string
+	User           string
+	TokenExpiresAt time.Time
+	ProjectRole    string
+	Checks         []*DiagnosticCheck
+}
+
+// Err returns error listing failed checks or nil if none of checks failed
+func (d *Diagnostics) Err() error {
+	var errs MultiError
+	for _, ch := range d.Checks {
+		if ch.Status == DiagnosticFailed {
+			errs = append(errs, errors.Errorf("%s: %s", ch.Name, ch.Message))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// String returns report with line per check
+func (d *Diagnostics) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "diagnostics of %s\n", d.Endpoint)
+	for _, ch := range d.Checks {
+		fmt.Fprintf(&sb, "[%s] %s: %s\n", ch.Status, ch.Name, ch.Message)
+	}
+	return sb.String()
+}
+
+// add adds check result to report
+func (d *Diagnostics) add(name, status, format string, args ...interface{}) {
+	d.Checks = append(d.Checks, &DiagnosticCheck{name, status, fmt.Sprintf(format, args...)})
+}
+
+// skip adds skipped results of checks to report
+func (d *Diagnostics) skip(names ...string) {
+	for _, name := range names {
+		d.add(name, DiagnosticSkipped, "previous check failed")
+	}
+}
+
+// Diagnose checks reachability of endpoint, TLS, API version compatibility, token validity and expiry,
+// project existence and role of user on it. Returns report, Err of report lists failed checks
+func (c *Client) Diagnose() *Diagnostics {
+	d := &Diagnostics{Endpoint: c.Endpoint}
+	if !c.diagnoseServer(d) {
+		d.skip(CheckToken, CheckProject)
+		return d
+	}
+	u, ok := c.diagnoseToken(d)
+	if !ok {
+		d.skip(CheckProject)
+		return d
+	}
+	c.diagnoseProject(d, u)
+	return d
+}
+
+// diagnoseServer checks reachability, TLS and API version of server. Returns false if server can't be used
+func (c *Client) diagnoseServer(d *Diagnostics) bool {
+	url := serverRoot(c.Endpoint) + "/api/info"
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		d.add(CheckReachability, DiagnosticFailed, "invalid endpoint %s: %v", c.Endpoint, err)
+		d.skip(CheckTLS, CheckAPIVersion)
+		return false
+	}
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		if isCertificateError(err) {
+			d.add(CheckReachability, DiagnosticOK, "%s is reachable", req.URL.Host)
+			d.add(CheckTLS, DiagnosticFailed, "certificate of %s is not trusted: %v; install CA certificate of server or fix endpoint host", req.URL.Host, err)
+		} else {
+			d.add(CheckReachability, DiagnosticFailed, "can't reach %s: %v; check endpoint URL, DNS and proxy settings", req.URL.Host, err)
+			d.skip(CheckTLS)
+		}
+		d.skip(CheckAPIVersion)
+		return false
+	}
+	defer resp.Body.Close()
+	d.add(CheckReachability, DiagnosticOK, "%s responded with status %s", req.URL.Host, resp.Status)
+
+	if resp.TLS == nil {
+		d.add(CheckTLS, DiagnosticWarning, "connection is not encrypted, use https endpoint")
+	} else {
+		d.TLSVersion = tlsVersionName(resp.TLS.Version)
+		if len(resp.TLS.PeerCertificates) > 0 {
+			d.CertExpiresAt = resp.TLS.PeerCertificates[0].NotAfter
+		}
+		if time.Until(d.CertExpiresAt) < expiryWarning {
+			d.add(CheckTLS, DiagnosticWarning, "%s, certificate expires at %s", d.TLSVersion, d.CertExpiresAt.Format(time.RFC3339))
+		} else {
+			d.add(CheckTLS, DiagnosticOK, "%s, certificate is valid until %s", d.TLSVersion, d.CertExpiresAt.Format(time.RFC3339))
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		d.add(CheckAPIVersion, DiagnosticWarning, "version is unknown, %s responded with status %s", url, resp.Status)
+		return true
+	}
+	var info struct {
+		Build struct {
+			Version string `json:"version"`
+		} `json:"build"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil || info.Build.Version == "" {
+		d.add(CheckAPIVersion, DiagnosticWarning, "version is unknown, %s responded with unexpected body", url)
+		return true
+	}
+	d.APIVersion = info.Build.Version
+
+	major, err := strconv.Atoi(strings.SplitN(d.APIVersion, ".", 2)[0])
+	switch {
+	case err != nil:
+		d.add(CheckAPIVersion, DiagnosticWarning, "version %s can't be parsed", d.APIVersion)
+	case major < 4:
+		d.add(CheckAPIVersion, DiagnosticFailed, "version %s is not supported, ReportPortal 4 or later is required", d.APIVersion)
+	case c.ServerVersion > 0 && c.ServerVersion != major:
+		d.add(CheckAPIVersion, DiagnosticFailed, "version %s doesn't match ServerVersion %d of client; set ServerVersion to %d", d.APIVersion, c.ServerVersion, major)
+	default:
+		d.add(CheckAPIVersion, DiagnosticOK, "version %s", d.APIVersion)
+	}
+	return true
+}
+
+// diagnoseToken checks expiry and validity of token. Returns user owning token and false if token can't be used
+func (c *Client) diagnoseToken(d *Diagnostics) (*UserResource, bool) {
+	exp, ok := tokenExpiry(c.Token)
+	if ok {
+		d.TokenExpiresAt = exp
+		if time.Now().After(exp) {
+			d.add(CheckToken, DiagnosticFailed, "token expired at %s; generate new token on profile page of ReportPortal", exp.Format(time.RFC3339))
+			return nil, false
+		}
+	}
+
+	u, err := c.GetCurrentUser()
+	if err != nil {
+		d.add(CheckToken, DiagnosticFailed, "token is rejected: %v; check token on profile page of ReportPortal", err)
+		return nil, false
+	}
+	d.User = u.Login
+
+	switch {
+	case !ok:
+		d.add(CheckToken, DiagnosticOK, "token of %s is valid and doesn't expire", u.Login)
+	case time.Until(exp) < expiryWarning:
+		d.add(CheckToken, DiagnosticWarning, "token of %s expires at %s", u.Login, exp.Format(time.RFC3339))
+	default:
+		d.add(CheckToken, DiagnosticOK, "token of %s is valid until %s", u.Login, exp.Format(time.RFC3339))
+	}
+	return u, true
+}
+
+// diagnoseProject checks existence of client project and role of user on it
+func (c *Client) diagnoseProject(d *Diagnostics, u *UserResource) {
+	if _, err := c.GetProject(); err != nil {
+		d.add(CheckProject, DiagnosticFailed, "project %q is not available: %v; check project name and access of %s to it", c.Project, err, u.Login)
+		return
+	}
+
+	if p, ok := u.AssignedProjects[c.Project]; ok {
+		d.ProjectRole = p.ProjectRole
+		d.add(CheckProject, DiagnosticOK, "%s has role %s on project %q", u.Login, p.ProjectRole, c.Project)
+	} else if u.UserRole == AccountRoleAdministrator {
+		d.add(CheckProject, DiagnosticWarning, "%s is administrator not assigned to project %q", u.Login, c.Project)
+	} else {
+		d.add(CheckProject, DiagnosticFailed, "%s is not assigned to project %q; ask project manager to assign user", u.Login, c.Project)
+	}
+}
+
+// serverRoot returns url of server without API path
+func serverRoot(endpoint string) string {
+	if i := strings.Index(endpoint, "/api/v"); i >= 0 {
+		return endpoint[:i]
+	}
+	return endpoint
+}
+
+// tokenExpiry returns expiry time of JWT token, false for tokens without expiry
+func tokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
+
+// isCertificateError reports whether err is caused by certificate verification
+func isCertificateError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	return stderrors.As(err, &unknownAuthority) || stderrors.As(err, &hostname) || stderrors.As(err, &invalid)
+}
+
+// tlsVersionName returns name of TLS version
+func tlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	}
+	return fmt.Sprintf("TLS 0x%04x", version)
+}
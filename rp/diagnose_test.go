@@ -0,0 +1,153 @@
+package rp
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// jwtToken returns unsigned JWT token expiring at exp
+func jwtToken(exp time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix())))
+	return "eyJhbGciOiJub25lIn0." + payload + ".sig"
+}
+
+// newDiagnoseServer starts fake ReportPortal with specified user and API version
+func newDiagnoseServer(version, user string) *httptest.Server {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/info":
+			fmt.Fprintf(w, `{"build":{"version":"%s"}}`, version)
+		case "/api/v1/user":
+			if r.Header.Get("Authorization") == "Bearer wrong" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(user))
+		case "/api/v1/project/test_project":
+			w.Write([]byte(`{"projectName":"test_project"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	return httptest.NewServer(h)
+}
+
+func checkStatuses(d *Diagnostics) []string {
+	var s []string
+	for _, ch := range d.Checks {
+		s = append(s, ch.Name+" "+ch.Status)
+	}
+	return s
+}
+
+func TestDiagnose(t *testing.T) {
+	member := `{"userId":"jdoe","userRole":"USER","assignedProjects":{"test_project":{"projectRole":"MEMBER"}}}`
+
+	t.Run("Healthy", func(t *testing.T) {
+		s := newDiagnoseServer("5.7.2", member)
+		defer s.Close()
+
+		c := NewClient(s.URL, "test_project", jwtToken(time.Now().Add(30*24*time.Hour)), 1)
+		c.ServerVersion = 5
+		d := c.Diagnose()
+		assert.NoError(t, d.Err())
+		assert.Equal(t, []string{
+			"reachability OK",
+			"tls WARNING",
+			"api version OK",
+			"token OK",
+			"project OK",
+		}, checkStatuses(d))
+		assert.Equal(t, "5.7.2", d.APIVersion)
+		assert.Equal(t, "jdoe", d.User)
+		assert.Equal(t, ProjectRoleMember, d.ProjectRole)
+		assert.False(t, d.TokenExpiresAt.IsZero())
+		assert.Contains(t, d.String(), "[OK] project: jdoe has role MEMBER on project \"test_project\"")
+	})
+
+	t.Run("Version mismatch and unassigned user", func(t *testing.T) {
+		s := newDiagnoseServer("4.3.0", `{"userId":"jdoe","userRole":"USER","assignedProjects":{}}`)
+		defer s.Close()
+
+		c := NewClient(s.URL, "test_project", "uuid-token", 1)
+		c.ServerVersion = 5
+		d := c.Diagnose()
+		assert.EqualError(t, d.Err(), "api version: version 4.3.0 doesn't match ServerVersion 5 of client; set ServerVersion to 4; "+
+			`project: jdoe is not assigned to project "test_project"; ask project manager to assign user`)
+		assert.True(t, d.TokenExpiresAt.IsZero())
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		s := newDiagnoseServer("5.0.0", member)
+		defer s.Close()
+
+		c := NewClient(s.URL, "test_project", jwtToken(time.Now().Add(-time.Hour)), 1)
+		d := c.Diagnose()
+		assert.Error(t, d.Err())
+		assert.Equal(t, []string{
+			"reachability OK",
+			"tls WARNING",
+			"api version OK",
+			"token FAILED",
+			"project SKIPPED",
+		}, checkStatuses(d))
+	})
+
+	t.Run("Rejected token", func(t *testing.T) {
+		s := newDiagnoseServer("5.0.0", member)
+		defer s.Close()
+
+		c := NewClient(s.URL, "test_project", "wrong", 1)
+		d := c.Diagnose()
+		assert.Contains(t, d.Err().Error(), "token: token is rejected: failed with status 401 Unauthorized")
+	})
+
+	t.Run("Untrusted certificate", func(t *testing.T) {
+		s := httptest.NewTLSServer(http.NotFoundHandler())
+		defer s.Close()
+
+		c := NewClient(s.URL, "test_project", "token", 1)
+		d := c.Diagnose()
+		assert.Equal(t, []string{
+			"reachability OK",
+			"tls FAILED",
+			"api version SKIPPED",
+			"token SKIPPED",
+			"project SKIPPED",
+		}, checkStatuses(d))
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		s := httptest.NewServer(http.NotFoundHandler())
+		s.Close()
+
+		c := NewClient(s.URL, "test_project", "token", 1)
+		d := c.Diagnose()
+		assert.Equal(t, []string{
+			"reachability FAILED",
+			"tls SKIPPED",
+			"api version SKIPPED",
+			"token SKIPPED",
+			"project SKIPPED",
+		}, checkStatuses(d))
+		assert.Contains(t, d.Err().Error(), "check endpoint URL, DNS and proxy settings")
+	})
+}
+
+func TestTokenExpiry(t *testing.T) {
+	exp := time.Unix(1900000000, 0)
+	e, ok := tokenExpiry(jwtToken(exp))
+	assert.True(t, ok)
+	assert.Equal(t, exp, e)
+
+	_, ok = tokenExpiry("0b9a8c1e-6f25-4c4b-9d0f-2c1a3e5b7d90")
+	assert.False(t, ok)
+	_, ok = tokenExpiry("a.!!!.c")
+	assert.False(t, ok)
+}
@@ -0,0 +1,416 @@
+package rp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	FilterTypeLaunch   = "launch"
+	FilterTypeTestItem = "testItem"
+	FilterTypeLog      = "log"
+
+	ConditionEq  = "eq"
+	ConditionCnt = "cnt"
+	ConditionHas = "has"
+	ConditionIn  = "in"
+	ConditionGte = "gte"
+	ConditionLte = "lte"
+	ConditionBtw = "btw"
+
+	FilterFieldName        = "name"
+	FilterFieldDescription = "description"
+	FilterFieldAttributes  = "compositeAttribute"
+	FilterFieldStatus      = "status"
+	FilterFieldStartTime   = "startTime"
+	FilterFieldEndTime     = "endTime"
+	FilterFieldNumber      = "number"
+	FilterFieldMode        = "mode"
+	FilterFieldUser        = "user"
+	FilterFieldType        = "type"
+)
+
+// FilterCondition defines condition of filter on field
+type FilterCondition struct {
+	FilteringField string `json:"filteringField"`
+	Condition      string `json:"condition"`
+	Value          string `json:"value"`
+}
+
+// FilterOrder defines sort order of filter
+type FilterOrder struct {
+	SortingColumn string `json:"sortingColumnName"`
+	IsAsc         bool   `json:"isAsc"`
+}
+
+// UserFilter defines saved filter of launches, test items or logs
+type UserFilter struct {
+	Id          string             `json:"id,omitempty"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Type        string             `json:"type"`
+	Share       bool               `json:"share"`
+	Owner       string             `json:"owner,omitempty"`
+	Conditions  []*FilterCondition `json:"conditions"`
+	Orders      []*FilterOrder     `json:"orders"`
+}
+
+// UserFilterPage defines page of saved filters
+type UserFilterPage = Page[*UserFilter]
+
+// LaunchResource defines launch stored in ReportPortal
+type LaunchResource struct {
+	Id          string    `json:"id"`
+	Number      int       `json:"number"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+	Mode        string    `json:"mode"`
+	Owner       string    `json:"owner"`
+	StartTime   time.Time `json:"startTime"`
+	EndTime     time.Time `json:"endTime"`
+}
+
+// LaunchPage defines page of launches
+type LaunchPage = Page[*LaunchResource]
+
+// TestItemResource defines test item stored in ReportPortal
+type TestItemResource struct {
+	Id          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Type        string    `json:"type"`
+	Status      string    `json:"status"`
+	LaunchId    string    `json:"launchId"`
+	Parent      string    `json:"parent"`
+	UniqueId    string    `json:"uniqueId"`
+	StartTime   time.Time `json:"startTime"`
+	EndTime     time.Time `json:"endTime"`
+}
+
+// TestItemPage defines page of test items
+type TestItemPage = Page[*TestItemResource]
+
+// Eq creates condition matching field equal to value
+func Eq(field, value string) *FilterCondition {
+	return &FilterCondition{field, ConditionEq, value}
+}
+
+// Cnt creates condition matching field containing value
+func Cnt(field, value string) *FilterCondition {
+	return &FilterCondition{field, ConditionCnt, value}
+}
+
+// Has creates condition matching collection field having all values
+func Has(field string, values ...string) *FilterCondition {
+	return &FilterCondition{field, ConditionHas, strings.Join(values, ",")}
+}
+
+// In creates condition matching field equal to any of values
+func In(field string, values ...string) *FilterCondition {
+	return &FilterCondition{field, ConditionIn, strings.Join(values, ",")}
+}
+
+// Gte creates condition matching field greater than or equal to value
+func Gte(field, value string) *FilterCondition {
+	return &FilterCondition{field, ConditionGte, value}
+}
+
+// Lte creates condition matching field less than or equal to value
+func Lte(field, value string) *FilterCondition {
+	return &FilterCondition{field, ConditionLte, value}
+}
+
+// Btw creates condition matching field between from and to inclusively
+func Btw(field, from, to string) *FilterCondition {
+	return &FilterCondition{field, ConditionBtw, from + "," + to}
+}
+
+// BtwTime creates condition matching time field between from and to inclusively.
+// Zero from or to leaves range open on that side with lte or gte condition, condition
+// without bounds for both zero times is rejected as invalid
+func BtwTime(field string, from, to time.Time) *FilterCondition {
+	switch {
+	case from.IsZero() && !to.IsZero():
+		return Lte(field, timeBound(to))
+	case to.IsZero() && !from.IsZero():
+		return Gte(field, timeBound(from))
+	}
+	return Btw(field, timeBound(from), timeBound(to))
+}
+
+// timeBound returns timestamp of t or empty string for zero t
+func timeBound(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return strconv.FormatInt(toTimestamp(t), 10)
+}
+
+// validate checks that btw condition has both bounds
+func (fc *FilterCondition) validate() error {
+	if fc.Condition != ConditionBtw {
+		return nil
+	}
+	bounds := strings.Split(fc.Value, ",")
+	if len(bounds) != 2 || bounds[0] == "" || bounds[1] == "" {
+		return errors.Errorf("btw condition on %s requires both bounds, got %q", fc.FilteringField, fc.Value)
+	}
+	return nil
+}
+
+// validateConditions checks conditions of filter
+func validateConditions(conditions []*FilterCondition) error {
+	for _, c := range conditions {
+		if c == nil {
+			return errors.New("nil filter condition")
+		}
+		if err := c.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Asc creates ascending sort order by field
+func Asc(field string) *FilterOrder {
+	return &FilterOrder{field, true}
+}
+
+// Desc creates descending sort order by field
+func Desc(field string) *FilterOrder {
+	return &FilterOrder{field, false}
+}
+
+// CreateFilter creates new saved filter. Returns id of created filter
+func (c *Client) CreateFilter(f *UserFilter) (string, error) {
+	url := fmt.Sprintf("%s/%s/filter", c.endpoint(), c.Project)
+	if err := validateConditions(f.Conditions); err != nil {
+		return "", errors.Wrap(err, "invalid filter")
+	}
+
+	b, err := json.Marshal(f)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to marshal object %v", f)
+	}
+
+	r := bytes.NewReader(b)
+	req, err := http.NewRequest(http.MethodPost, url, r)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create POST request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to execute POST request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	v := struct {
+		Id string `json:"id"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return "", errors.Wrapf(err, "failed to decode response from %s", req.URL)
+	}
+	return v.Id, nil
+}
+
+// GetFilter gets saved filter with specified id
+func (c *Client) GetFilter(id string) (*UserFilter, error) {
+	url := fmt.Sprintf("%s/%s/filter/%s", c.endpoint(), c.Project, id)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't create GET request for %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute GET request for %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	var f *UserFilter
+	if err := json.NewDecoder(resp.Body).Decode(&f); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response for filter")
+	}
+	return f, nil
+}
+
+// GetFilters gets saved filters of project matching query
+func (c *Client) GetFilters(q *Query) (*UserFilterPage, error) {
+	url, err := listURL(fmt.Sprintf("%s/%s/filter", c.endpoint(), c.Project), q, nil)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't create GET request for %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute GET request for %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	var fp *UserFilterPage
+	if err := json.NewDecoder(resp.Body).Decode(&fp); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response for filters")
+	}
+	return fp, nil
+}
+
+// IterFilters iterates over saved filters of project matching query
+func (c *Client) IterFilters(q *Query) *Iterator[*UserFilter] {
+	return NewIterator(func(number int) (*UserFilterPage, error) {
+		return c.GetFilters(q.withPage(number))
+	})
+}
+
+// UpdateFilter updates saved filter with id of f
+func (c *Client) UpdateFilter(f *UserFilter) error {
+	url := fmt.Sprintf("%s/%s/filter/%s", c.endpoint(), c.Project, f.Id)
+	if err := validateConditions(f.Conditions); err != nil {
+		return errors.Wrap(err, "invalid filter")
+	}
+
+	b, err := json.Marshal(f)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal object %v", f)
+	}
+
+	r := bytes.NewReader(b)
+	req, err := http.NewRequest(http.MethodPut, url, r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create PUT request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute PUT request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+	return nil
+}
+
+// DeleteFilter deletes saved filter with specified id
+func (c *Client) DeleteFilter(id string) error {
+	url := fmt.Sprintf("%s/%s/filter/%s", c.endpoint(), c.Project, id)
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create DELETE request for %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute DELETE request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+	return nil
+}
+
+// SearchLaunches gets launches matching query
+func (c *Client) SearchLaunches(q *Query) (*LaunchPage, error) {
+	url, err := listURL(fmt.Sprintf("%s/%s/launch", c.endpoint(), c.Project), q, nil)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't create GET request for %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute GET request for %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	var lp *LaunchPage
+	if err := json.NewDecoder(resp.Body).Decode(&lp); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response for launches")
+	}
+	return lp, nil
+}
+
+// IterLaunches iterates over launches matching query
+func (c *Client) IterLaunches(q *Query) *Iterator[*LaunchResource] {
+	return NewIterator(func(number int) (*LaunchPage, error) {
+		return c.SearchLaunches(q.withPage(number))
+	})
+}
+
+// SearchTestItems gets test items of launch matching query
+func (c *Client) SearchTestItems(launchId string, q *Query) (*TestItemPage, error) {
+	extra := url.Values{"filter.eq.launchId": {launchId}}
+	url, err := listURL(fmt.Sprintf("%s/%s/item", c.endpoint(), c.Project), q, extra)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't create GET request for %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute GET request for %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	var tp *TestItemPage
+	if err := json.NewDecoder(resp.Body).Decode(&tp); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response for test items")
+	}
+	return tp, nil
+}
+
+// IterTestItems iterates over test items of launch matching query
+func (c *Client) IterTestItems(launchId string, q *Query) *Iterator[*TestItemResource] {
+	return NewIterator(func(number int) (*TestItemPage, error) {
+		return c.SearchTestItems(launchId, q.withPage(number))
+	})
+}
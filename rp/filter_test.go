@@ -0,0 +1,213 @@
+package rp
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterConditions(t *testing.T) {
+	from := time.Unix(1, 0)
+	to := time.Unix(2, 0)
+	assert.Equal(t, &FilterCondition{"name", "eq", "smoke"}, Eq(FilterFieldName, "smoke"))
+	assert.Equal(t, &FilterCondition{"name", "cnt", "smo"}, Cnt(FilterFieldName, "smo"))
+	assert.Equal(t, &FilterCondition{"compositeAttribute", "has", "os:linux,ci"}, Has(FilterFieldAttributes, "os:linux", "ci"))
+	assert.Equal(t, &FilterCondition{"status", "in", "FAILED,INTERRUPTED"}, In(FilterFieldStatus, StatusFailed, StatusInterrupted))
+	assert.Equal(t, &FilterCondition{"number", "gte", "5"}, Gte(FilterFieldNumber, "5"))
+	assert.Equal(t, &FilterCondition{"number", "lte", "9"}, Lte(FilterFieldNumber, "9"))
+	assert.Equal(t, &FilterCondition{"startTime", "btw", "1000,2000"}, BtwTime(FilterFieldStartTime, from, to))
+	assert.Equal(t, &FilterCondition{"startTime", "gte", "1000"}, BtwTime(FilterFieldStartTime, from, time.Time{}))
+	assert.Equal(t, &FilterCondition{"startTime", "lte", "2000"}, BtwTime(FilterFieldStartTime, time.Time{}, to))
+	assert.Equal(t, &FilterOrder{"startTime", true}, Asc(FilterFieldStartTime))
+	assert.Equal(t, &FilterOrder{"number", false}, Desc(FilterFieldNumber))
+}
+
+func TestCreateFilter(t *testing.T) {
+	t.Run("Successful create", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/test_project/filter", r.URL.Path)
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			d, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, `{"name":"failed","description":"","type":"launch","share":false,`+
+				`"conditions":[{"filteringField":"status","condition":"in","value":"FAILED,INTERRUPTED"}],`+
+				`"orders":[{"sortingColumnName":"startTime","isAsc":false}]}`, string(d))
+
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": "fid1"}`))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		}
+
+		id, err := c.CreateFilter(&UserFilter{
+			Name:       "failed",
+			Type:       FilterTypeLaunch,
+			Conditions: []*FilterCondition{In(FilterFieldStatus, StatusFailed, StatusInterrupted)},
+			Orders:     []*FilterOrder{Desc(FilterFieldStartTime)},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "fid1", id)
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+		}
+
+		id, err := c.CreateFilter(&UserFilter{})
+		assert.Empty(t, id)
+		assert.EqualError(t, err, "failed with status 400 Bad Request")
+	})
+
+	t.Run("Open range", func(t *testing.T) {
+		var body string
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			body = string(d)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"fid1"}`))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{Endpoint: s.URL, Project: "test_project"}
+		id, err := c.CreateFilter(&UserFilter{
+			Conditions: []*FilterCondition{BtwTime(FilterFieldStartTime, time.Unix(1, 0), time.Time{})},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "fid1", id)
+		assert.Contains(t, body, `{"filteringField":"startTime","condition":"gte","value":"1000"}`)
+	})
+
+	t.Run("No bounds", func(t *testing.T) {
+		c := &Client{}
+		id, err := c.CreateFilter(&UserFilter{
+			Conditions: []*FilterCondition{BtwTime(FilterFieldStartTime, time.Time{}, time.Time{})},
+		})
+		assert.Empty(t, id)
+		assert.EqualError(t, err, `invalid filter: btw condition on startTime requires both bounds, got ","`)
+	})
+}
+
+func TestGetFilter(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		switch r.URL.Path {
+		case "/test_project/filter/fid1":
+			w.Write([]byte(`{"id":"fid1","name":"failed","type":"launch","owner":"user",` +
+				`"conditions":[{"filteringField":"status","condition":"eq","value":"FAILED"}],"orders":[]}`))
+		case "/test_project/filter":
+			w.Write([]byte(`{"content":[{"id":"fid1","name":"failed"}],"page":{"number":1,"size":20,"totalElements":1,"totalPages":1}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := &Client{
+		Endpoint: s.URL,
+		Project:  "test_project",
+	}
+
+	f, err := c.GetFilter("fid1")
+	assert.NoError(t, err)
+	assert.Equal(t, "user", f.Owner)
+	assert.Equal(t, []*FilterCondition{Eq(FilterFieldStatus, StatusFailed)}, f.Conditions)
+
+	fp, err := c.GetFilters(nil)
+	assert.NoError(t, err)
+	assert.Len(t, fp.Content, 1)
+	assert.Equal(t, 1, fp.Page.TotalElements)
+
+	_, err = c.GetFilter("unknown")
+	assert.EqualError(t, err, "failed with status 404 Not Found")
+}
+
+func TestUpdateDeleteFilter(t *testing.T) {
+	var methods []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/test_project/filter/fid1", r.URL.Path)
+		methods = append(methods, r.Method)
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := &Client{
+		Endpoint: s.URL,
+		Project:  "test_project",
+	}
+
+	assert.NoError(t, c.UpdateFilter(&UserFilter{Id: "fid1", Name: "renamed"}))
+	assert.NoError(t, c.DeleteFilter("fid1"))
+	assert.Equal(t, []string{"PUT", "DELETE"}, methods)
+}
+
+func TestSearchLaunches(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/test_project/launch", r.URL.Path)
+		q := r.URL.Query()
+		assert.Equal(t, "smoke", q.Get("filter.cnt.name"))
+		assert.Equal(t, "FAILED,INTERRUPTED", q.Get("filter.in.status"))
+		assert.Equal(t, []string{"startTime,DESC", "number,ASC"}, q["page.sort"])
+
+		w.Write([]byte(`{"content":[{"id":"lid1","number":3,"name":"smoke","status":"FAILED","startTime":"2019-01-01T10:00:00Z"}],` +
+			`"page":{"number":1,"size":20,"totalElements":1,"totalPages":1}}`))
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := &Client{
+		Endpoint: s.URL,
+		Project:  "test_project",
+	}
+
+	lp, err := c.SearchLaunches(NewQuery().
+		Where(Cnt(FilterFieldName, "smoke"), In(FilterFieldStatus, StatusFailed, StatusInterrupted)).
+		OrderBy(Desc(FilterFieldStartTime), Asc(FilterFieldNumber)))
+	assert.NoError(t, err)
+	assert.Len(t, lp.Content, 1)
+	assert.Equal(t, 3, lp.Content[0].Number)
+	assert.Equal(t, time.Date(2019, 1, 1, 10, 0, 0, 0, time.UTC), lp.Content[0].StartTime)
+}
+
+func TestSearchTestItems(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/test_project/item", r.URL.Path)
+		q := r.URL.Query()
+		assert.Equal(t, "lid1", q.Get("filter.eq.launchId"))
+		assert.Equal(t, "FAILED", q.Get("filter.eq.status"))
+
+		w.Write([]byte(`{"content":[{"id":"iid1","name":"test","type":"STEP","status":"FAILED","launchId":"lid1"}]}`))
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := &Client{
+		Endpoint: s.URL,
+		Project:  "test_project",
+	}
+
+	tp, err := c.SearchTestItems("lid1", NewQuery().Eq(FilterFieldStatus, StatusFailed))
+	assert.NoError(t, err)
+	assert.Len(t, tp.Content, 1)
+	assert.Equal(t, "iid1", tp.Content[0].Id)
+}
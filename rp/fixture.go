@@ -0,0 +1,82 @@
+package rp
+
+import (
+	"github.com/pkg/errors"
+)
+
+// beforeFixtures defines test item types of setup fixtures
+var beforeFixtures = map[string]bool{
+	TestItemBeforeClass:  true,
+	TestItemBeforeGroups: true,
+	TestItemBeforeMethod: true,
+	TestItemBeforeSuite:  true,
+	TestItemBeforeTest:   true,
+}
+
+// afterFixtures defines test item types of teardown fixtures
+var afterFixtures = map[string]bool{
+	TestItemAfterClass:  true,
+	TestItemAfterGroups: true,
+	TestItemAfterMethod: true,
+	TestItemAfterSuite:  true,
+	TestItemAfterTest:   true,
+}
+
+// Fixture reports fn as setup or teardown fixture of specified type under the test item.
+// Failed setup fixture makes all following steps and setup fixtures of the test item and its descendants skipped
+func (ti *TestItem) Fixture(itemType, name string, fn func(fixture *TestItem) error) error {
+	return runFixture(ti.launch, ti, itemType, name, fn)
+}
+
+// Fixture reports fn as top level setup or teardown fixture of specified type.
+// Failed setup fixture makes all following steps and setup fixtures of the launch skipped
+func (l *Launch) Fixture(itemType, name string, fn func(fixture *TestItem) error) error {
+	return runFixture(l, nil, itemType, name, fn)
+}
+
+// FixtureErr returns error of failed setup fixture of the test item, its ancestors or launch
+func (ti *TestItem) FixtureErr() error {
+	for i := ti; i != nil; i = i.Parent {
+		if i.fixtureErr != nil {
+			return i.fixtureErr
+		}
+	}
+	if ti.launch != nil {
+		return ti.launch.fixtureErr
+	}
+	return nil
+}
+
+// runFixture reports fn as fixture under parent test item or launch when parent is nil
+func runFixture(launch *Launch, parent *TestItem, itemType, name string, fn func(fixture *TestItem) error) error {
+	before := beforeFixtures[itemType]
+	if !before && !afterFixtures[itemType] {
+		return errors.Errorf("%s is not a fixture type", itemType)
+	}
+
+	fixture := NewTestItem(launch, name, "", itemType, nil, parent)
+	if !before {
+		return fixture.run(fn)
+	}
+
+	var failed error
+	if parent != nil {
+		failed = parent.FixtureErr()
+	} else {
+		failed = launch.fixtureErr
+	}
+	if failed != nil {
+		return fixture.skip(failed)
+	}
+
+	err := fixture.run(fn)
+	if err != nil {
+		failed = errors.Wrapf(err, "fixture %s failed", name)
+		if parent != nil {
+			parent.fixtureErr = failed
+		} else {
+			launch.fixtureErr = failed
+		}
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+package rp
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fixtureServer records names, types and finish statuses of reported items
+type fixtureServer struct {
+	mu       sync.Mutex
+	count    int
+	started  []string
+	statuses map[string]string
+}
+
+func (fs *fixtureServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	d, _ := ioutil.ReadAll(r.Body)
+	switch {
+	case r.Method == "POST" && strings.HasPrefix(r.URL.Path, "/test_project/item"):
+		fs.count++
+		id := fmt.Sprintf("item%d", fs.count)
+		fs.started = append(fs.started, id+" "+string(d))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id": "%s"}`, id)
+	case r.Method == "PUT":
+		fs.statuses[strings.TrimPrefix(r.URL.Path, "/test_project/item/")] = string(d)
+	default:
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func TestFixture(t *testing.T) {
+	newItem := func(s *httptest.Server) *TestItem {
+		c := &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		}
+		return &TestItem{
+			Id:     "parent",
+			launch: &Launch{Id: "id123", client: c},
+			client: c,
+		}
+	}
+
+	t.Run("Successful fixture", func(t *testing.T) {
+		fs := &fixtureServer{statuses: map[string]string{}}
+		s := httptest.NewServer(fs)
+		defer s.Close()
+
+		ti := newItem(s)
+		err := ti.Fixture(TestItemBeforeMethod, "setup", func(fixture *TestItem) error {
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, ti.FixtureErr())
+
+		assert.Len(t, fs.started, 1)
+		assert.Contains(t, fs.started[0], `"type":"BEFORE_METHOD"`)
+		assert.Contains(t, fs.statuses["item1"], `"status":"PASSED"`)
+	})
+
+	t.Run("Failed before fixture skips steps", func(t *testing.T) {
+		fs := &fixtureServer{statuses: map[string]string{}}
+		s := httptest.NewServer(fs)
+		defer s.Close()
+
+		ti := newItem(s)
+		err := ti.Fixture(TestItemBeforeMethod, "setup", func(fixture *TestItem) error {
+			return errors.New("no database")
+		})
+		assert.EqualError(t, err, "no database")
+		assert.EqualError(t, ti.FixtureErr(), "fixture setup failed: no database")
+
+		called := false
+		err = ti.Step("step", func(step *TestItem) error {
+			called = true
+			return nil
+		})
+		assert.False(t, called)
+		assert.EqualError(t, err, "step skipped: fixture setup failed: no database")
+
+		err = ti.Fixture(TestItemAfterMethod, "teardown", func(fixture *TestItem) error {
+			called = true
+			return nil
+		})
+		assert.True(t, called)
+		assert.NoError(t, err)
+
+		assert.Contains(t, fs.statuses["item1"], `"status":"FAILED"`)
+		assert.Contains(t, fs.statuses["item2"], `"status":"SKIPPED"`)
+		assert.Contains(t, fs.statuses["item3"], `"status":"PASSED"`)
+	})
+
+	t.Run("Failed launch fixture skips nested steps", func(t *testing.T) {
+		fs := &fixtureServer{statuses: map[string]string{}}
+		s := httptest.NewServer(fs)
+		defer s.Close()
+
+		ti := newItem(s)
+		err := ti.launch.Fixture(TestItemBeforeSuite, "setup", func(fixture *TestItem) error {
+			return errors.New("no database")
+		})
+		assert.Error(t, err)
+
+		err = ti.Step("step", func(step *TestItem) error {
+			return nil
+		})
+		assert.EqualError(t, err, "step skipped: fixture setup failed: no database")
+	})
+
+	t.Run("Wrong fixture type", func(t *testing.T) {
+		ti := &TestItem{launch: &Launch{}}
+		err := ti.Fixture(TestItemStep, "step", func(fixture *TestItem) error {
+			return nil
+		})
+		assert.EqualError(t, err, "STEP is not a fixture type")
+	})
+}
@@ -3,14 +3,116 @@ package rp
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
+// MultiError defines errors occurred during bulk operation
+type MultiError []error
+
+// Error joins messages of all errors
+func (me MultiError) Error() string {
+	msgs := make([]string, len(me))
+	for i, err := range me {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// appendErr appends err to errs, errors of MultiError are appended one by one
+func appendErr(errs MultiError, err error) MultiError {
+	switch e := err.(type) {
+	case nil:
+		return errs
+	case MultiError:
+		return append(errs, e...)
+	default:
+		return append(errs, e)
+	}
+}
+
+// Attribute defines key-value attribute of launch or test item in ReportPortal 5
+type Attribute struct {
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value"`
+}
+
+// tagsToAttributes converts tags in key:value form to attributes, tags without key become values
+func tagsToAttributes(tags []string) []*Attribute {
+	if tags == nil {
+		return nil
+	}
+	attrs := make([]*Attribute, 0, len(tags))
+	for _, t := range tags {
+		if i := strings.Index(t, ":"); i > 0 {
+			attrs = append(attrs, &Attribute{t[:i], t[i+1:]})
+		} else {
+			attrs = append(attrs, &Attribute{Value: t})
+		}
+	}
+	return attrs
+}
+
 // toTimestamp returns unix timestamp for time object
 func toTimestamp(t time.Time) int64 {
 	return t.Unix() * int64(time.Microsecond)
 }
 
+// rollup accumulates statuses of test items for status rollup
+type rollup struct {
+	total       int
+	skipped     int
+	failed      bool
+	interrupted bool
+	stopped     bool
+}
+
+// add accounts status of test item, empty status is status of not finished test item
+func (r *rollup) add(status string) {
+	r.total++
+	switch status {
+	case StatusFailed:
+		r.failed = true
+	case StatusInterrupted, "":
+		r.interrupted = true
+	case StatusStopped, StatusCanceled:
+		r.stopped = true
+	case StatusSkipped:
+		r.skipped++
+	}
+}
+
+// rollupStatus derives status from statuses accumulated in finished and statuses of items:
+// failed if any item failed, interrupted if any item interrupted or not finished, stopped
+// if any item stopped or canceled, skipped if all items skipped and passed otherwise
+func rollupStatus(finished rollup, items []*TestItem) string {
+	r := finished
+	for _, ti := range items {
+		r.add(ti.Status())
+	}
+	switch {
+	case r.failed:
+		return StatusFailed
+	case r.interrupted:
+		return StatusInterrupted
+	case r.stopped:
+		return StatusStopped
+	case r.total > 0 && r.skipped == r.total:
+		return StatusSkipped
+	}
+	return StatusPassed
+}
+
+// removeItem returns items without ti
+func removeItem(items []*TestItem, ti *TestItem) []*TestItem {
+	for i, item := range items {
+		if item == ti {
+			return append(items[:i], items[i+1:]...)
+		}
+	}
+	return items
+}
+
 // doRequest do request with authorization token
 func doRequest(req *http.Request, token string) (*http.Response, error) {
 	auth := fmt.Sprintf("Bearer %s", token)
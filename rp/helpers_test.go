@@ -24,3 +24,36 @@ func TestDoRequest(t *testing.T) {
 	mockReq := httptest.NewRequest(http.MethodGet, mockServer.URL, nil)
 	doRequest(mockReq, "1234")
 }
+
+func TestRollupStatus(t *testing.T) {
+	items := func(statuses ...string) []*TestItem {
+		var res []*TestItem
+		for _, s := range statuses {
+			res = append(res, &TestItem{status: s})
+		}
+		return res
+	}
+
+	var rollups = []struct {
+		name     string
+		items    []*TestItem
+		expected string
+	}{
+		{"No items", nil, StatusPassed},
+		{"Passed", items(StatusPassed, StatusPassed), StatusPassed},
+		{"Failed", items(StatusPassed, StatusFailed, StatusSkipped), StatusFailed},
+		{"Skipped", items(StatusSkipped, StatusSkipped), StatusSkipped},
+		{"Partially skipped", items(StatusSkipped, StatusPassed), StatusPassed},
+		{"Interrupted", items(StatusPassed, StatusInterrupted, StatusStopped), StatusInterrupted},
+		{"Unfinished", items(StatusPassed, ""), StatusInterrupted},
+		{"Failed and unfinished", items("", StatusFailed), StatusFailed},
+		{"Stopped", items(StatusSkipped, StatusStopped), StatusStopped},
+		{"Canceled", items(StatusPassed, StatusCanceled), StatusStopped},
+	}
+
+	for _, tt := range rollups {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rollupStatus(rollup{}, tt.items))
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -19,7 +20,13 @@ type Launch struct {
 	StartTime   time.Time
 	Tags        []string
 
-	client *Client
+	client     *Client
+	fixtureErr error
+
+	mu       sync.Mutex
+	status   string
+	items    []*TestItem
+	finished rollup
 }
 
 // NewLaunch creates new launch for specified client
@@ -35,14 +42,20 @@ func NewLaunch(client *Client, name, description, mode string, tags []string) *L
 
 // Start starts the launch
 func (l *Launch) Start() error {
-	url := fmt.Sprintf("%s/%s/launch", l.client.Endpoint, l.client.Project)
+	url := fmt.Sprintf("%s/%s/launch", l.client.endpoint(), l.client.Project)
 	launch := struct {
-		Name        string   `json:"name"`
-		Description string   `json:"description"`
-		Mode        string   `json:"mode"`
-		Tags        []string `json:"tags,omitempty"`
-		StartTime   int64    `json:"start_time"`
-	}{l.Name, l.Description, l.Mode, l.Tags, toTimestamp(time.Now())}
+		Name        string       `json:"name"`
+		Description string       `json:"description"`
+		Mode        string       `json:"mode"`
+		Tags        []string     `json:"tags,omitempty"`
+		Attributes  []*Attribute `json:"attributes,omitempty"`
+		StartTime   int64        `json:"start_time"`
+	}{Name: l.Name, Description: l.Description, Mode: l.Mode, StartTime: toTimestamp(time.Now())}
+	if l.client.features().Attributes {
+		launch.Attributes = tagsToAttributes(l.Tags)
+	} else {
+		launch.Tags = l.Tags
+	}
 
 	b, err := json.Marshal(&launch)
 	if err != nil {
@@ -58,10 +71,10 @@ func (l *Launch) Start() error {
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := doRequest(req, l.client.Token)
-	defer resp.Body.Close()
 	if err != nil {
 		return errors.Wrapf(err, "failed to execute POST request %s", req.URL)
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusCreated {
 		return errors.Errorf("failed with status %s", resp.Status)
 	}
@@ -73,6 +86,7 @@ func (l *Launch) Start() error {
 		return errors.Wrapf(err, "failed to decode response from %s", req.URL)
 	}
 	l.Id = v.Id
+	l.client.addLaunch(l)
 	return nil
 }
 
@@ -81,14 +95,31 @@ func (l *Launch) Stop(status string) error {
 	return l.finalize(status, ActionStop)
 }
 
-// Finish finishes launch
+// Finish finishes launch, launch level logs accumulated by LogBatcher are flushed before.
+// When status is empty and client has StatusRollup enabled, status is derived from top level test items
 func (l *Launch) Finish(status string) error {
+	if status == "" && l.client.StatusRollup {
+		l.mu.Lock()
+		status = rollupStatus(l.finished, l.items)
+		l.mu.Unlock()
+	}
 	return l.finalize(status, ActionFinish)
 }
 
+// Log sends log for specified launch which isn't tied to any test item, level must be one of Level... constants.
+// When client has LogBatcher, log is added to the batch and sent later
+func (l *Launch) Log(message, level string, attachment *Attachment) error {
+	return l.client.SendLog(&LogEntry{
+		Message:    message,
+		Level:      Level(level),
+		Attachment: attachment,
+		LaunchId:   l.Id,
+	})
+}
+
 // Delete delete launch
 func (l *Launch) Delete() error {
-	url := fmt.Sprintf("%s/%s/launch/%s", l.client.Endpoint, l.client.Project, l.Id)
+	url := fmt.Sprintf("%s/%s/launch/%s", l.client.endpoint(), l.client.Project, l.Id)
 
 	req, err := http.NewRequest(http.MethodDelete, url, nil)
 	if err != nil {
@@ -98,10 +129,10 @@ func (l *Launch) Delete() error {
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := doRequest(req, l.client.Token)
-	defer resp.Body.Close()
 	if err != nil {
 		return errors.Wrapf(err, "failed to execute PUT request %s", req.URL)
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		return errors.Errorf("failed with status %s", resp.Status)
 	}
@@ -110,12 +141,19 @@ func (l *Launch) Delete() error {
 
 // Update updates launch
 func (l *Launch) Update(description, mode string, tags []string) error {
-	url := fmt.Sprintf("%s/%s/launch/%s/update", l.client.Endpoint, l.client.Project, l.Id)
-	data := struct {
+	url := fmt.Sprintf("%s/%s/launch/%s/update", l.client.endpoint(), l.client.Project, l.Id)
+	var data interface{} = struct {
 		Description string   `json:"description"`
 		Mode        string   `json:"mode"`
 		Tags        []string `json:"tags"`
 	}{description, mode, tags}
+	if l.client.features().Attributes {
+		data = struct {
+			Description string       `json:"description"`
+			Mode        string       `json:"mode"`
+			Attributes  []*Attribute `json:"attributes"`
+		}{description, mode, tagsToAttributes(tags)}
+	}
 
 	b, err := json.Marshal(&data)
 	if err != nil {
@@ -131,19 +169,47 @@ func (l *Launch) Update(description, mode string, tags []string) error {
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := doRequest(req, l.client.Token)
-	defer resp.Body.Close()
 	if err != nil {
 		return errors.Wrapf(err, "failed to execute PUT request %s", req.URL)
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		return errors.Errorf("failed with status %s", resp.Status)
 	}
 	return nil
 }
 
-// finalize finishes launch with specified status and action
+// Status returns status with which launch was finished or stopped, empty for running launch
+func (l *Launch) Status() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.status
+}
+
+// addItem registers started top level test item
+func (l *Launch) addItem(ti *TestItem) {
+	l.mu.Lock()
+	l.items = append(l.items, ti)
+	l.mu.Unlock()
+}
+
+// removeItem unregisters finished top level test item keeping its status for rollup
+func (l *Launch) removeItem(ti *TestItem, status string) {
+	l.mu.Lock()
+	l.items = removeItem(l.items, ti)
+	l.finished.add(status)
+	l.mu.Unlock()
+}
+
+// finalize finishes launch with specified status and action, launch level logs
+// accumulated by LogBatcher are flushed before
 func (l *Launch) finalize(status, action string) error {
-	url := fmt.Sprintf("%s/%s/launch/%s/%s", l.client.Endpoint, l.client.Project, l.Id, action)
+	var flushErr error
+	if l.client.LogBatcher != nil {
+		flushErr = l.client.LogBatcher.FlushLaunch(l.Id)
+	}
+
+	url := fmt.Sprintf("%s/%s/launch/%s/%s", l.client.endpoint(), l.client.Project, l.Id, action)
 	data := struct {
 		Status  string `json:"status"`
 		EndTime int64  `json:"end_time"`
@@ -163,12 +229,22 @@ func (l *Launch) finalize(status, action string) error {
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := doRequest(req, l.client.Token)
-	defer resp.Body.Close()
 	if err != nil {
 		return errors.Wrapf(err, "failed to execute PUT request %s", req.URL)
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		return errors.Errorf("failed with status %s", resp.Status)
 	}
+
+	l.mu.Lock()
+	l.status = status
+	l.mu.Unlock()
+
+	l.client.removeLaunch(l)
+
+	if flushErr != nil {
+		return errors.Wrap(flushErr, "failed to flush logs")
+	}
 	return nil
 }
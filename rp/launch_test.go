@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -41,6 +42,31 @@ func TestStartLaunch(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("Attributes for server v5", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Contains(t, string(d), `"attributes":[{"key":"os","value":"linux"},{"value":"smoke"}]`)
+			assert.NotContains(t, string(d), `"tags"`)
+
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": "testid"}`))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint:      s.URL,
+			Project:       "test_project",
+			ServerVersion: 5,
+		}
+		l := &Launch{
+			client: c,
+			Tags:   []string{"os:linux", "smoke"},
+		}
+		assert.NoError(t, l.Start())
+	})
+
 	t.Run("Differ status code", func(t *testing.T) {
 		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
@@ -263,3 +289,108 @@ func TestUpdateLaunch(t *testing.T) {
 		assert.EqualError(t, err, "failed with status 500 Internal Server Error")
 	})
 }
+
+func TestFinishLaunchWithRollup(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(d), `"status":"SKIPPED"`)
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	l := &Launch{
+		client: &Client{
+			Endpoint:     s.URL,
+			Project:      "test_project",
+			StatusRollup: true,
+		},
+		Id: "id123",
+	}
+	l.addItem(&TestItem{status: StatusSkipped})
+	l.addItem(&TestItem{status: StatusSkipped})
+
+	err := l.Finish("")
+	assert.NoError(t, err)
+}
+
+func TestLogLaunch(t *testing.T) {
+	t.Run("Successful write without attachment", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/test_project/log", r.URL.Path)
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			d, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+
+			rx, _ := regexp.Compile(`\{\"launchUuid\"\:\"id123\"\,\"message\"\:\"log message\"\,\"level\"\:\"info\"\,\"time\"\:\d+\}`)
+			assert.Regexp(t, rx, string(d))
+
+			w.WriteHeader(http.StatusCreated)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		l := &Launch{
+			Id: "id123",
+			client: &Client{
+				Endpoint: s.URL,
+				Project:  "test_project",
+			},
+		}
+
+		err := l.Log("log message", LevelInfo, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Successful write with attachment", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/test_project/log", r.URL.Path)
+			assert.Contains(t, r.Header.Get("Content-Type"), "multipart/form-data; boundary=")
+
+			d, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+
+			rx, _ := regexp.Compile(`\[\{\"file"\:\{\"name":\"env\.txt"},\"launchUuid\"\:\"id123\"\,\"level\"\:\"info\"\,\"message\"\:\"environment\"\,\"time\"\:\d+\}\]`)
+			assert.Regexp(t, rx, string(d))
+			assert.Contains(t, string(d), `Content-Disposition: form-data; name="file"; filename="env.txt"`)
+			assert.Contains(t, string(d), `GOOS=linux`)
+			w.WriteHeader(http.StatusCreated)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		l := &Launch{
+			Id: "id123",
+			client: &Client{
+				Endpoint: s.URL,
+				Project:  "test_project",
+			},
+		}
+
+		err := l.Log("environment", LevelInfo, &Attachment{
+			Name:     "env.txt",
+			MimeType: "text/plain",
+			Data:     strings.NewReader("GOOS=linux"),
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		l := &Launch{
+			client: &Client{
+				Endpoint: s.URL,
+			},
+		}
+
+		err := l.Log("", LevelInfo, nil)
+		assert.EqualError(t, err, "failed with status 500 Internal Server Error")
+	})
+}
@@ -0,0 +1,372 @@
+package rp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// defaultMaxRetained is number of logs retained after failed sends when MaxRetained isn't set
+const defaultMaxRetained = 1000
+
+// LogBatcher accumulates logs with attachments and sends them in multipart batches
+type LogBatcher struct {
+	MaxEntries int
+	MaxBytes   int64
+	// MaxRetained limits number of logs retained for next send after failed sends,
+	// oldest logs beyond the limit are dropped. Zero means 1000
+	MaxRetained int
+
+	client *Client
+
+	mu      sync.Mutex
+	entries []*batchEntry
+	size    int64
+	errs    MultiError
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// batchEntry defines log entry waiting to be sent
+type batchEntry struct {
+	entry    jsonRequestEntry
+	data     []byte
+	mimeType string
+}
+
+// LogFailure defines log entry rejected by ReportPortal. LaunchId is set for launch level logs only
+type LogFailure struct {
+	ItemId   string
+	LaunchId string
+	Message  string
+	Reason   string
+}
+
+// Error returns description of rejected log entry
+func (lf *LogFailure) Error() string {
+	if lf.ItemId == "" {
+		return fmt.Sprintf("log %q of launch %s rejected: %s", lf.Message, lf.LaunchId, lf.Reason)
+	}
+	return fmt.Sprintf("log %q of item %s rejected: %s", lf.Message, lf.ItemId, lf.Reason)
+}
+
+// NewLogBatcher creates new log batcher which sends batch when it has maxEntries entries,
+// its size reaches maxBytes or each interval. Zero value disables corresponding threshold
+func NewLogBatcher(client *Client, maxEntries int, maxBytes int64, interval time.Duration) *LogBatcher {
+	b := &LogBatcher{
+		MaxEntries: maxEntries,
+		MaxBytes:   maxBytes,
+		client:     client,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+
+	if interval <= 0 {
+		close(b.done)
+		return b
+	}
+
+	go func() {
+		defer close(b.done)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				if err := b.send(); err != nil {
+					b.mu.Lock()
+					b.errs = appendErr(b.errs, err)
+					b.mu.Unlock()
+				}
+			case <-b.stop:
+				return
+			}
+		}
+	}()
+	return b
+}
+
+// Add adds log of test item to the batch, attachment is read immediately.
+// Batch is sent when one of thresholds is reached
+func (b *LogBatcher) Add(itemId, message, level string, attachment *Attachment) error {
+	return b.AddEntry(&LogEntry{
+		Message:    message,
+		Level:      Level(level),
+		Attachment: attachment,
+		ItemId:     itemId,
+	})
+}
+
+// AddEntry adds log entry to the batch, attachment is read and closed immediately.
+// Batch is sent when one of thresholds is reached
+func (b *LogBatcher) AddEntry(le *LogEntry) error {
+	if le.Attachment != nil {
+		defer le.Attachment.Close()
+	}
+
+	entry, err := le.requestEntry()
+	if err != nil {
+		return err
+	}
+
+	e := &batchEntry{entry: entry}
+	if attachment := le.Attachment; attachment != nil {
+		name, mimeType, data, gz := prepareAttachment(b.client, attachment)
+		buf := &bytes.Buffer{}
+		if err := copyAttachment(b.client, buf, attachment.Name, data, gz); err != nil {
+			return err
+		}
+		e.entry.File = &fileInfo{name}
+		e.data = buf.Bytes()
+		e.mimeType = mimeType
+	}
+
+	b.mu.Lock()
+	b.entries = append(b.entries, e)
+	b.size += int64(len(entry.Message) + len(e.data))
+	full := (b.MaxEntries > 0 && len(b.entries) >= b.MaxEntries) || (b.MaxBytes > 0 && b.size >= b.MaxBytes)
+	b.mu.Unlock()
+
+	if full {
+		return b.send()
+	}
+	return nil
+}
+
+// Flush sends all accumulated logs. Returned error contains failures of previous
+// background sends and *LogFailure for every rejected or dropped log entry
+func (b *LogBatcher) Flush() error {
+	err := b.send()
+	return b.takeErrs(err, func(error) bool { return true })
+}
+
+// FlushItem sends all accumulated logs. Returned error contains *LogFailure for every
+// rejected or dropped log entry of specified test item, failures of other items are kept
+// for their flushes
+func (b *LogBatcher) FlushItem(itemId string) error {
+	err := b.send()
+	return b.takeErrs(err, func(e error) bool {
+		lf, ok := e.(*LogFailure)
+		return ok && lf.ItemId == itemId
+	})
+}
+
+// FlushLaunch sends all accumulated logs. Returned error contains *LogFailure for every
+// rejected or dropped launch level log of specified launch, failures of other logs are kept
+// for their flushes
+func (b *LogBatcher) FlushLaunch(launchId string) error {
+	err := b.send()
+	return b.takeErrs(err, func(e error) bool {
+		lf, ok := e.(*LogFailure)
+		return ok && lf.ItemId == "" && lf.LaunchId == launchId
+	})
+}
+
+// Close stops periodic sending and flushes all accumulated logs.
+// Logs which can't be sent are dropped
+func (b *LogBatcher) Close() error {
+	select {
+	case <-b.stop:
+	default:
+		close(b.stop)
+	}
+	<-b.done
+
+	err := b.send()
+	b.mu.Lock()
+	entries := b.entries
+	b.entries = nil
+	b.size = 0
+	b.drop(entries, err)
+	b.mu.Unlock()
+	return b.takeErrs(err, func(error) bool { return true })
+}
+
+// takeErrs removes recorded errors matching filter. Returns them with err
+func (b *LogBatcher) takeErrs(err error, match func(error) bool) error {
+	b.mu.Lock()
+	var errs, rest MultiError
+	for _, e := range b.errs {
+		if match(e) {
+			errs = append(errs, e)
+		} else {
+			rest = append(rest, e)
+		}
+	}
+	b.errs = rest
+	b.mu.Unlock()
+
+	errs = appendErr(errs, err)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// send sends accumulated logs in one request. Logs of failed request are retained for next send,
+// rejected logs and logs of undecodable response are recorded as failures. Returns error of request
+func (b *LogBatcher) send() error {
+	b.mu.Lock()
+	entries := b.entries
+	b.entries = nil
+	b.size = 0
+	b.mu.Unlock()
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	resp, err := b.post(entries)
+	if err != nil {
+		b.retain(entries, err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	v := struct {
+		Responses []struct {
+			Id      string `json:"id"`
+			Message string `json:"message"`
+		} `json:"responses"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		// logs may be saved already, so they aren't retained to avoid duplicates
+		err = errors.Wrapf(err, "failed to decode response from %s", resp.Request.URL)
+		b.mu.Lock()
+		b.drop(entries, err)
+		b.mu.Unlock()
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, r := range v.Responses {
+		if r.Id != "" || i >= len(entries) {
+			continue
+		}
+		b.errs = append(b.errs, &LogFailure{
+			ItemId:   entries[i].entry.ItemId,
+			LaunchId: entries[i].entry.LaunchUuid,
+			Message:  entries[i].entry.Message,
+			Reason:   r.Message,
+		})
+	}
+	return nil
+}
+
+// post sends entries in one request. Returns response with status 201
+func (b *LogBatcher) post(entries []*batchEntry) (*http.Response, error) {
+	req, err := b.getReqForBatch(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := doRequest(req, b.client.Token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute POST request %s", req.URL)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		resp.Body.Close()
+		return nil, errors.Errorf("failed to send batch of %d logs with status %s", len(entries), resp.Status)
+	}
+	return resp, nil
+}
+
+// retain puts entries of failed send before accumulated logs, oldest logs beyond
+// MaxRetained are dropped
+func (b *LogBatcher) retain(entries []*batchEntry, cause error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	limit := b.MaxRetained
+	if limit <= 0 {
+		limit = defaultMaxRetained
+	}
+	all := append(entries, b.entries...)
+	if len(all) > limit {
+		b.drop(all[:len(all)-limit], cause)
+		all = all[len(all)-limit:]
+	}
+	b.entries = all
+	b.size = 0
+	for _, e := range all {
+		b.size += int64(len(e.entry.Message) + len(e.data))
+	}
+}
+
+// drop records failures for entries which won't be sent. Must be called with locked mutex
+func (b *LogBatcher) drop(entries []*batchEntry, cause error) {
+	reason := "dropped"
+	if cause != nil {
+		reason = "dropped after failed send: " + cause.Error()
+	}
+	for _, e := range entries {
+		b.errs = append(b.errs, &LogFailure{
+			ItemId:   e.entry.ItemId,
+			LaunchId: e.entry.LaunchUuid,
+			Message:  e.entry.Message,
+			Reason:   reason,
+		})
+	}
+}
+
+// getReqForBatch creates multipart request with all entries and their attachments
+func (b *LogBatcher) getReqForBatch(entries []*batchEntry) (*http.Request, error) {
+	url := fmt.Sprintf("%s/%s/log", b.client.endpoint(), b.client.Project)
+	bodyBuf := &bytes.Buffer{}
+	bodyWriter := multipart.NewWriter(bodyBuf)
+
+	// json request part
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", `form-data; name="json_request_part"`)
+	h.Set("Content-Type", "application/json")
+	reqWriter, err := bodyWriter.CreatePart(h)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create form file")
+	}
+
+	jsonReqPart := make(jsonRequestPart, len(entries))
+	for i, e := range entries {
+		jsonReqPart[i] = e.entry
+	}
+	if err := json.NewEncoder(reqWriter).Encode(&jsonReqPart); err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal to JSON: %v", jsonReqPart)
+	}
+
+	// files
+	for _, e := range entries {
+		if e.entry.File == nil {
+			continue
+		}
+
+		h = make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, "file", e.entry.File.Name))
+		h.Set("Content-Type", e.mimeType)
+
+		fileWriter, err := bodyWriter.CreatePart(h)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create form file")
+		}
+		if _, err := fileWriter.Write(e.data); err != nil {
+			return nil, errors.Wrap(err, "failed to write file")
+		}
+	}
+
+	bodyWriter.Close()
+
+	req, err := http.NewRequest(http.MethodPost, url, bodyBuf)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create POST request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", bodyWriter.FormDataContentType())
+	return req, nil
+}
@@ -0,0 +1,290 @@
+package rp
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// batchServer collects json request parts and file names of received batches
+type batchServer struct {
+	mu        sync.Mutex
+	batches   [][]jsonRequestEntry
+	files     []string
+	responses string
+}
+
+func (bs *batchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if r.Method == "PUT" {
+		return
+	}
+
+	_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	mr := multipart.NewReader(r.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		if p.FormName() == "json_request_part" {
+			var entries []jsonRequestEntry
+			json.NewDecoder(p).Decode(&entries)
+			bs.batches = append(bs.batches, entries)
+		} else {
+			d, _ := ioutil.ReadAll(p)
+			bs.files = append(bs.files, p.FileName()+":"+string(d))
+		}
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(bs.responses))
+}
+
+func (bs *batchServer) batchCount() int {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	return len(bs.batches)
+}
+
+func TestLogBatcherFlush(t *testing.T) {
+	t.Run("Successful flush", func(t *testing.T) {
+		bs := &batchServer{responses: `{"responses":[{"id":"1"},{"id":"2"}]}`}
+		s := httptest.NewServer(bs)
+		defer s.Close()
+
+		b := NewLogBatcher(&Client{Endpoint: s.URL, Project: "test_project"}, 0, 0, 0)
+		assert.NoError(t, b.Add("item1", "first", LevelInfo, nil))
+		assert.NoError(t, b.Add("item2", "second", LevelError, &Attachment{
+			Name:     "test.txt",
+			Data:     strings.NewReader("file content"),
+			MimeType: "text/plain",
+		}))
+		assert.Equal(t, 0, bs.batchCount())
+
+		assert.NoError(t, b.Flush())
+		assert.Len(t, bs.batches, 1)
+		assert.Len(t, bs.batches[0], 2)
+		assert.Equal(t, "first", bs.batches[0][0].Message)
+		assert.Nil(t, bs.batches[0][0].File)
+		assert.Equal(t, "item2", bs.batches[0][1].ItemId)
+		assert.Equal(t, &fileInfo{"test.txt"}, bs.batches[0][1].File)
+		assert.Equal(t, []string{"test.txt:file content"}, bs.files)
+
+		assert.NoError(t, b.Flush())
+		assert.Len(t, bs.batches, 1)
+	})
+
+	t.Run("Rejected entries", func(t *testing.T) {
+		bs := &batchServer{responses: `{"responses":[{"id":"1"},{"message":"item not found"}]}`}
+		s := httptest.NewServer(bs)
+		defer s.Close()
+
+		b := NewLogBatcher(&Client{Endpoint: s.URL, Project: "test_project"}, 0, 0, 0)
+		b.Add("item1", "first", LevelInfo, nil)
+		b.Add("item2", "second", LevelInfo, nil)
+
+		err := b.Flush()
+		assert.EqualError(t, err, `log "second" of item item2 rejected: item not found`)
+		assert.Equal(t, &LogFailure{ItemId: "item2", Message: "second", Reason: "item not found"}, err.(MultiError)[0])
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		b := NewLogBatcher(&Client{Endpoint: s.URL}, 0, 0, 0)
+		b.Add("item1", "first", LevelInfo, nil)
+
+		err := b.Flush()
+		assert.EqualError(t, err, "failed to send batch of 1 logs with status 500 Internal Server Error")
+	})
+}
+
+func TestLogBatcherFailedSend(t *testing.T) {
+	// newServer starts batch server responding with 503 while failing is set
+	newServer := func(bs *batchServer, failing *bool) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if *failing {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			bs.ServeHTTP(w, r)
+		}))
+	}
+
+	t.Run("Retained for next send", func(t *testing.T) {
+		bs := &batchServer{responses: `{"responses":[]}`}
+		failing := true
+		s := newServer(bs, &failing)
+		defer s.Close()
+
+		b := NewLogBatcher(&Client{Endpoint: s.URL, Project: "test_project"}, 0, 0, 0)
+		b.Add("item1", "first", LevelInfo, nil)
+		assert.EqualError(t, b.Flush(), "failed to send batch of 1 logs with status 503 Service Unavailable")
+
+		failing = false
+		b.Add("item1", "second", LevelInfo, nil)
+		assert.NoError(t, b.Flush())
+		assert.Len(t, bs.batches, 1)
+		assert.Equal(t, "first", bs.batches[0][0].Message)
+		assert.Equal(t, "second", bs.batches[0][1].Message)
+	})
+
+	t.Run("Retained limit", func(t *testing.T) {
+		bs := &batchServer{responses: `{"responses":[]}`}
+		failing := true
+		s := newServer(bs, &failing)
+		defer s.Close()
+
+		b := NewLogBatcher(&Client{Endpoint: s.URL, Project: "test_project"}, 0, 0, 0)
+		b.MaxRetained = 2
+		b.Add("item1", "first", LevelInfo, nil)
+		b.Add("item2", "second", LevelInfo, nil)
+		b.Add("item2", "third", LevelInfo, nil)
+		assert.Error(t, b.FlushItem("item2"))
+
+		failing = false
+		err := b.FlushItem("item1")
+		assert.EqualError(t, err, `log "first" of item item1 rejected: dropped after failed send: `+
+			"failed to send batch of 3 logs with status 503 Service Unavailable")
+		assert.Len(t, bs.batches, 1)
+		assert.Len(t, bs.batches[0], 2)
+		assert.NoError(t, b.FlushItem("item2"))
+	})
+
+	t.Run("Failures of item", func(t *testing.T) {
+		bs := &batchServer{responses: `{"responses":[{"message":"item not found"},{"message":"item not found"}]}`}
+		s := httptest.NewServer(bs)
+		defer s.Close()
+
+		b := NewLogBatcher(&Client{Endpoint: s.URL, Project: "test_project"}, 0, 0, 0)
+		b.Add("item1", "first", LevelInfo, nil)
+		b.Add("item2", "second", LevelInfo, nil)
+
+		assert.EqualError(t, b.FlushItem("item1"), `log "first" of item item1 rejected: item not found`)
+		assert.NoError(t, b.FlushItem("item1"))
+		assert.EqualError(t, b.Flush(), `log "second" of item item2 rejected: item not found`)
+	})
+
+	t.Run("Undecodable response", func(t *testing.T) {
+		bs := &batchServer{responses: `not json`}
+		s := httptest.NewServer(bs)
+		defer s.Close()
+
+		b := NewLogBatcher(&Client{Endpoint: s.URL, Project: "test_project"}, 0, 0, 0)
+		b.Add("item1", "first", LevelInfo, nil)
+
+		err := b.FlushItem("item1")
+		if assert.Len(t, err, 2) {
+			lf := err.(MultiError)[0].(*LogFailure)
+			assert.Equal(t, "item1", lf.ItemId)
+			assert.True(t, strings.HasPrefix(lf.Reason, "dropped after failed send: failed to decode response"))
+		}
+		assert.NoError(t, b.Flush())
+		assert.Equal(t, 1, bs.batchCount())
+	})
+
+	t.Run("Dropped on close", func(t *testing.T) {
+		bs := &batchServer{}
+		failing := true
+		s := newServer(bs, &failing)
+		defer s.Close()
+
+		b := NewLogBatcher(&Client{Endpoint: s.URL, Project: "test_project"}, 0, 0, 0)
+		b.Add("item1", "first", LevelInfo, nil)
+
+		err := b.Close()
+		assert.Len(t, err, 2)
+		assert.Equal(t, &LogFailure{ItemId: "item1", Message: "first", Reason: "dropped after failed send: " +
+			"failed to send batch of 1 logs with status 503 Service Unavailable"}, err.(MultiError)[0])
+		assert.NoError(t, b.Flush())
+	})
+}
+
+func TestLogBatcherThresholds(t *testing.T) {
+	t.Run("Max entries", func(t *testing.T) {
+		bs := &batchServer{responses: `{"responses":[]}`}
+		s := httptest.NewServer(bs)
+		defer s.Close()
+
+		b := NewLogBatcher(&Client{Endpoint: s.URL, Project: "test_project"}, 2, 0, 0)
+		b.Add("item1", "first", LevelInfo, nil)
+		assert.Equal(t, 0, bs.batchCount())
+		b.Add("item1", "second", LevelInfo, nil)
+		assert.Equal(t, 1, bs.batchCount())
+	})
+
+	t.Run("Max bytes", func(t *testing.T) {
+		bs := &batchServer{responses: `{"responses":[]}`}
+		s := httptest.NewServer(bs)
+		defer s.Close()
+
+		b := NewLogBatcher(&Client{Endpoint: s.URL, Project: "test_project"}, 0, 10, 0)
+		b.Add("item1", "12345", LevelInfo, nil)
+		assert.Equal(t, 0, bs.batchCount())
+		b.Add("item1", "67890", LevelInfo, nil)
+		assert.Equal(t, 1, bs.batchCount())
+	})
+
+	t.Run("Interval", func(t *testing.T) {
+		bs := &batchServer{responses: `{"responses":[]}`}
+		s := httptest.NewServer(bs)
+		defer s.Close()
+
+		b := NewLogBatcher(&Client{Endpoint: s.URL, Project: "test_project"}, 0, 0, 10*time.Millisecond)
+		b.Add("item1", "first", LevelInfo, nil)
+		assert.Eventually(t, func() bool { return bs.batchCount() == 1 }, time.Second, 5*time.Millisecond)
+		assert.NoError(t, b.Close())
+	})
+}
+
+func TestLogBatcherTestItem(t *testing.T) {
+	bs := &batchServer{responses: `{"responses":[{"id":"1"}]}`}
+	s := httptest.NewServer(bs)
+	defer s.Close()
+
+	c := &Client{Endpoint: s.URL, Project: "test_project"}
+	c.LogBatcher = NewLogBatcher(c, 0, 0, 0)
+	ti := &TestItem{Id: "id123", client: c}
+
+	assert.NoError(t, ti.Log("message", LevelInfo, nil))
+	assert.Equal(t, 0, bs.batchCount())
+
+	assert.NoError(t, ti.Finish(StatusPassed))
+	assert.Equal(t, 1, bs.batchCount())
+}
+
+func TestLogBatcherLaunch(t *testing.T) {
+	bs := &batchServer{responses: `{"responses":[{"id":"1"},{"message":"launch not found"}]}`}
+	s := httptest.NewServer(bs)
+	defer s.Close()
+
+	c := &Client{Endpoint: s.URL, Project: "test_project"}
+	c.LogBatcher = NewLogBatcher(c, 0, 0, 0)
+	l := &Launch{Id: "launch1", client: c}
+
+	assert.NoError(t, l.Log("first", LevelInfo, nil))
+	assert.NoError(t, l.Log("second", LevelInfo, nil))
+	assert.Equal(t, 0, bs.batchCount())
+
+	err := l.Finish(StatusPassed)
+	assert.EqualError(t, err, `failed to flush logs: log "second" of launch launch1 rejected: launch not found`)
+	assert.Equal(t, 1, bs.batchCount())
+	assert.Equal(t, StatusPassed, l.Status())
+	assert.NoError(t, c.LogBatcher.Flush())
+}
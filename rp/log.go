@@ -0,0 +1,499 @@
+package rp
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Level defines log level
+type Level string
+
+// levelValues defines numeric values of log levels in ReportPortal
+var levelValues = map[Level]int{
+	LevelTrace:   5000,
+	LevelDebug:   10000,
+	LevelInfo:    20000,
+	LevelWarn:    30000,
+	LevelError:   40000,
+	LevelFatal:   50000,
+	LevelUnknown: 60000,
+}
+
+// customLevels holds levels registered with RegisterLevel
+var customLevels = struct {
+	sync.RWMutex
+	values map[Level]int
+}{values: map[Level]int{}}
+
+// RegisterLevel registers custom log level with case insensitive name and ReportPortal numeric value.
+// Registering the same name with the same value again is allowed
+func RegisterLevel(name string, value int) (Level, error) {
+	l := Level(strings.ToLower(name))
+	if l == "" {
+		return "", errors.New("level name is empty")
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		return "", errors.Errorf("level name %q is numeric", name)
+	}
+	if value <= 0 {
+		return "", errors.Errorf("invalid value %d of level %q", value, name)
+	}
+	if _, ok := levelValues[l]; ok {
+		return "", errors.Errorf("level %q is predefined", name)
+	}
+
+	customLevels.Lock()
+	defer customLevels.Unlock()
+	if v, ok := customLevels.values[l]; ok {
+		if v != value {
+			return "", errors.Errorf("level %q is already registered with value %d", name, v)
+		}
+		return l, nil
+	}
+	if other, ok := levelByValue(value); ok {
+		return "", errors.Errorf("value %d is already used by level %q", value, other)
+	}
+	customLevels.values[l] = value
+	return l, nil
+}
+
+// levelByValue finds level with numeric value, customLevels must be locked by caller
+func levelByValue(n int) (Level, bool) {
+	for l, v := range levelValues {
+		if v == n {
+			return l, true
+		}
+	}
+	for l, v := range customLevels.values {
+		if v == n {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+// ParseLevel parses case insensitive log level name or ReportPortal numeric log level, including registered custom levels
+func ParseLevel(s string) (Level, error) {
+	l := Level(strings.ToLower(s))
+	if l.Valid() {
+		return l, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		customLevels.RLock()
+		l, ok := levelByValue(n)
+		customLevels.RUnlock()
+		if ok {
+			return l, nil
+		}
+	}
+	return "", errors.Errorf("invalid log level %q", s)
+}
+
+// Valid checks whether level is one of Level... constants or registered custom level
+func (l Level) Valid() bool {
+	return l.Value() != 0
+}
+
+// Value returns ReportPortal numeric value of level, zero for invalid level
+func (l Level) Value() int {
+	if v, ok := levelValues[l]; ok {
+		return v
+	}
+	customLevels.RLock()
+	defer customLevels.RUnlock()
+	return customLevels.values[l]
+}
+
+// custom checks whether level is registered custom level
+func (l Level) custom() bool {
+	_, ok := levelValues[l]
+	return !ok && l.Valid()
+}
+
+// LogEntry defines log of test item or launch which can be built ahead of time and sent later.
+// Zero Time means time of sending, ItemId takes precedence over LaunchId
+type LogEntry struct {
+	Message    string
+	Level      Level
+	Time       time.Time
+	Attachment *Attachment
+	ItemId     string
+	LaunchId   string
+}
+
+// requestEntry validates log entry and converts it to entry of log request
+func (e *LogEntry) requestEntry() (jsonRequestEntry, error) {
+	level, err := ParseLevel(string(e.Level))
+	if err != nil {
+		return jsonRequestEntry{}, err
+	}
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	// ReportPortal knows custom levels only by numeric value
+	name := string(level)
+	if level.custom() {
+		name = strconv.Itoa(level.Value())
+	}
+	entry := jsonRequestEntry{
+		Level:   name,
+		Message: e.Message,
+		Time:    toTimestamp(t),
+	}
+	if e.ItemId != "" {
+		entry.ItemId = e.ItemId
+	} else {
+		entry.LaunchUuid = e.LaunchId
+	}
+	return entry, nil
+}
+
+// Attachment defines attachment for log request with file.
+// Data implementing io.Closer is closed when log is sent or rejected
+type Attachment struct {
+	Name     string
+	Data     io.Reader
+	MimeType string
+}
+
+// Close closes attachment data when it implements io.Closer
+func (a *Attachment) Close() error {
+	if c, ok := a.Data.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// fileInfo defines file structure for json request part
+type fileInfo struct {
+	Name string `json:"name"`
+}
+
+// jsonRequestEntry defines log entry of json request part
+type jsonRequestEntry struct {
+	File       *fileInfo `json:"file,omitempty"`
+	ItemId     string    `json:"item_id,omitempty"`
+	LaunchUuid string    `json:"launchUuid,omitempty"`
+	Level      string    `json:"level"`
+	Message    string    `json:"message"`
+	Time       int64     `json:"time"`
+}
+
+// jsonRequestPart defines request object for request with attachment
+type jsonRequestPart []jsonRequestEntry
+
+// BinaryContent defines info about attachment of log
+type BinaryContent struct {
+	Id          string `json:"id"`
+	ThumbnailId string `json:"thumbnailId"`
+	ContentType string `json:"contentType"`
+}
+
+// LogRecord defines log stored in ReportPortal
+type LogRecord struct {
+	Id            string         `json:"id"`
+	ItemId        string         `json:"itemId"`
+	Level         string         `json:"level"`
+	Message       string         `json:"message"`
+	Time          time.Time      `json:"time"`
+	BinaryContent *BinaryContent `json:"binaryContent"`
+}
+
+// LogPage defines page of logs
+type LogPage = Page[*LogRecord]
+
+// LogFilter defines filter for logs retrieval, zero fields are not applied
+type LogFilter struct {
+	Levels []string
+	From   time.Time
+	To     time.Time
+	Text   string
+	Page   int
+	Size   int
+}
+
+// Query returns query for filter
+func (f *LogFilter) Query() *Query {
+	q := NewQuery()
+	if f == nil {
+		return q
+	}
+	q.In("level", f.Levels...)
+	q.Between("logTime", f.From, f.To)
+	q.Cnt("message", f.Text)
+	if f.Page > 0 {
+		q.Page(f.Page)
+	}
+	if f.Size > 0 {
+		q.Size(f.Size)
+	}
+	return q
+}
+
+// Logs gets logs of specified test item matching filter, nil filter matches all logs
+func (ti *TestItem) Logs(f *LogFilter) (*LogPage, error) {
+	return ti.QueryLogs(f.Query())
+}
+
+// QueryLogs gets logs of specified test item matching query
+func (ti *TestItem) QueryLogs(q *Query) (*LogPage, error) {
+	extra := url.Values{"filter.eq.item": {ti.Id}}
+	url, err := listURL(fmt.Sprintf("%s/%s/log", ti.client.endpoint(), ti.client.Project), q, extra)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't create GET request for %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, ti.client.Token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute GET request for %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	var lp *LogPage
+	if err := json.NewDecoder(resp.Body).Decode(&lp); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response for logs")
+	}
+	return lp, nil
+}
+
+// IterLogs iterates over logs of specified test item matching query
+func (ti *TestItem) IterLogs(q *Query) *Iterator[*LogRecord] {
+	return NewIterator(func(number int) (*LogPage, error) {
+		return ti.QueryLogs(q.withPage(number))
+	})
+}
+
+// DownloadAttachment writes content of log attachment with specified binary id to w
+func (c *Client) DownloadAttachment(binaryId string, w io.Writer) error {
+	url := fmt.Sprintf("%s/%s/data/%s", c.endpoint(), c.Project, binaryId)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return errors.Wrapf(err, "can't create GET request for %s", url)
+	}
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute GET request for %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return errors.Wrapf(err, "failed to download attachment %s", binaryId)
+	}
+	return nil
+}
+
+// SendLog sends log entry. When client has LogBatcher, entry is added to the batch and sent later
+func (c *Client) SendLog(e *LogEntry) error {
+	if c.LogBatcher != nil {
+		return c.LogBatcher.AddEntry(e)
+	}
+	if e.Attachment != nil {
+		defer e.Attachment.Close()
+	}
+
+	entry, err := e.requestEntry()
+	if err != nil {
+		return err
+	}
+	return sendLog(c, entry, e.Attachment)
+}
+
+// sendLog sends log entry for test item or launch with optional attachment
+func sendLog(c *Client, entry jsonRequestEntry, attachment *Attachment) error {
+	var req *http.Request
+	var err error
+	if attachment != nil {
+		var done <-chan struct{}
+		req, done, err = getReqForLogWithAttach(c, entry, attachment)
+		if err != nil {
+			return err
+		}
+		// attachment must not be read when request is finished
+		defer func() {
+			req.Body.Close()
+			<-done
+		}()
+	} else {
+		req, err = getReqForLog(c, entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute POST request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+	return nil
+}
+
+// getReqForLogWithAttach creates request to perform log request with message and attachment.
+// Request body is streamed from attachment data without buffering, returned channel
+// is closed when streaming is finished
+func getReqForLogWithAttach(c *Client, entry jsonRequestEntry, attachment *Attachment) (*http.Request, <-chan struct{}, error) {
+	url := fmt.Sprintf("%s/%s/log", c.endpoint(), c.Project)
+	pr, pw := io.Pipe()
+	bodyWriter := multipart.NewWriter(pw)
+
+	req, err := http.NewRequest(http.MethodPost, url, pr)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to create POST request to %s", url)
+	}
+	req.Header.Set("Content-Type", bodyWriter.FormDataContentType())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pw.CloseWithError(writeLogWithAttach(c, bodyWriter, entry, attachment))
+	}()
+	return req, done, nil
+}
+
+// writeLogWithAttach writes json request part and attachment to multipart writer
+func writeLogWithAttach(c *Client, bodyWriter *multipart.Writer, entry jsonRequestEntry, attachment *Attachment) error {
+	name, mimeType, data, gz := prepareAttachment(c, attachment)
+
+	// json request part
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", `form-data; name="json_request_part"`)
+	h.Set("Content-Type", "application/json")
+	reqWriter, err := bodyWriter.CreatePart(h)
+	if err != nil {
+		return errors.Wrap(err, "failed to create form file")
+	}
+
+	entry.File = &fileInfo{name}
+	jsonReqPart := &jsonRequestPart{entry}
+	if err := json.NewEncoder(reqWriter).Encode(&jsonReqPart); err != nil {
+		return errors.Wrapf(err, "failed to marshal to JSON: %v", jsonReqPart)
+	}
+
+	// file
+	h = make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, "file", name))
+	h.Set("Content-Type", mimeType)
+
+	fileWriter, err := bodyWriter.CreatePart(h)
+	if err != nil {
+		return errors.Wrap(err, "failed to create form file")
+	}
+	if err := copyAttachment(c, fileWriter, attachment.Name, data, gz); err != nil {
+		return err
+	}
+
+	return bodyWriter.Close()
+}
+
+// prepareAttachment detects mime type of attachment when it's not specified
+// and whether attachment should be compressed. Returns name, mime type and data to send
+func prepareAttachment(c *Client, attachment *Attachment) (string, string, io.Reader, bool) {
+	name := attachment.Name
+	mimeType := attachment.MimeType
+	var data io.Reader = attachment.Data
+	if mimeType == "" {
+		br := bufio.NewReader(attachment.Data)
+		head, _ := br.Peek(512)
+		mimeType = http.DetectContentType(head)
+		data = br
+	}
+
+	gz := c.CompressAttachments && isTextMimeType(mimeType)
+	if gz {
+		name += ".gz"
+		mimeType = "application/gzip"
+	}
+	return name, mimeType, data, gz
+}
+
+// copyAttachment copies attachment data to w compressing it with gzip when gz is set.
+// Fails when data is bigger than MaxAttachmentSize of the client
+func copyAttachment(c *Client, w io.Writer, name string, data io.Reader, gz bool) error {
+	if c.MaxAttachmentSize > 0 {
+		data = io.LimitReader(data, c.MaxAttachmentSize+1)
+	}
+
+	var zw *gzip.Writer
+	if gz {
+		zw = gzip.NewWriter(w)
+		w = zw
+	}
+
+	n, err := io.Copy(w, data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to copy attachment %s", name)
+	}
+	if c.MaxAttachmentSize > 0 && n > c.MaxAttachmentSize {
+		return errors.Errorf("attachment %s exceeds size limit of %d bytes", name, c.MaxAttachmentSize)
+	}
+
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return errors.Wrapf(err, "failed to compress attachment %s", name)
+		}
+	}
+	return nil
+}
+
+// isTextMimeType checks whether mime type defines text content
+func isTextMimeType(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "text/") || strings.Contains(mimeType, "json") || strings.Contains(mimeType, "xml")
+}
+
+// getReqForLog creates request to perform log request with message
+func getReqForLog(c *Client, entry jsonRequestEntry) (*http.Request, error) {
+	url := fmt.Sprintf("%s/%s/log", c.endpoint(), c.Project)
+	data := struct {
+		ItemId     string `json:"item_id,omitempty"`
+		LaunchUuid string `json:"launchUuid,omitempty"`
+		Message    string `json:"message"`
+		Level      string `json:"level"`
+		Time       int64  `json:"time"`
+	}{entry.ItemId, entry.LaunchUuid, entry.Message, entry.Level, entry.Time}
+
+	b, err := json.Marshal(&data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal object, %v", data)
+	}
+
+	r := bytes.NewReader(b)
+	req, err := http.NewRequest(http.MethodPost, url, r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create POST request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
@@ -0,0 +1,357 @@
+package rp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamingAttachment(t *testing.T) {
+	// readFile returns file name, content type and content of file part
+	readFile := func(t *testing.T, r *http.Request) (string, string, []byte) {
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		assert.NoError(t, err)
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if err != nil {
+				return "", "", nil
+			}
+			if p.FormName() == "file" {
+				d, err := ioutil.ReadAll(p)
+				assert.NoError(t, err)
+				return p.FileName(), p.Header.Get("Content-Type"), d
+			}
+		}
+	}
+
+	t.Run("Detect mime type", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name, mimeType, d := readFile(t, r)
+			assert.Equal(t, "page.html", name)
+			assert.Equal(t, "text/html; charset=utf-8", mimeType)
+			assert.Equal(t, "<html><body></body></html>", string(d))
+			w.WriteHeader(http.StatusCreated)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{Endpoint: s.URL, Project: "test_project"}
+		err := sendLog(c, jsonRequestEntry{ItemId: "id123"}, &Attachment{
+			Name: "page.html",
+			Data: strings.NewReader("<html><body></body></html>"),
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Compress text attachment", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name, mimeType, d := readFile(t, r)
+			assert.Equal(t, "output.txt.gz", name)
+			assert.Equal(t, "application/gzip", mimeType)
+
+			zr, err := gzip.NewReader(bytes.NewReader(d))
+			assert.NoError(t, err)
+			content, err := ioutil.ReadAll(zr)
+			assert.NoError(t, err)
+			assert.Equal(t, "some output", string(content))
+			w.WriteHeader(http.StatusCreated)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{Endpoint: s.URL, Project: "test_project", CompressAttachments: true}
+		err := sendLog(c, jsonRequestEntry{ItemId: "id123"}, &Attachment{
+			Name:     "output.txt",
+			Data:     strings.NewReader("some output"),
+			MimeType: "text/plain",
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Exceeded size limit", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ioutil.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{Endpoint: s.URL, Project: "test_project", MaxAttachmentSize: 4}
+		err := sendLog(c, jsonRequestEntry{ItemId: "id123"}, &Attachment{
+			Name:     "big.bin",
+			Data:     strings.NewReader("12345"),
+			MimeType: "application/octet-stream",
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "attachment big.bin exceeds size limit of 4 bytes")
+	})
+}
+
+func TestLogsTestItem(t *testing.T) {
+	t.Run("Successful result", func(t *testing.T) {
+		okResponse := `{
+			"content": [
+				{
+					"id": "log1",
+					"itemId": "id123",
+					"level": "error",
+					"message": "assertion failed",
+					"time": "2019-07-22T10:10:10.000Z",
+					"binaryContent": {"id": "bin1", "thumbnailId": "thumb1", "contentType": "image/png"}
+				}
+			],
+			"page": {"number": 2, "size": 10, "totalElements": 11, "totalPages": 2}
+		}`
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/test_project/log", r.URL.Path)
+			assert.Equal(t, "GET", r.Method)
+
+			q := r.URL.Query()
+			assert.Equal(t, "id123", q.Get("filter.eq.item"))
+			assert.Equal(t, "error,warn", q.Get("filter.in.level"))
+			assert.Equal(t, "1546300800000", q.Get("filter.gte.logTime"))
+			assert.Equal(t, "", q.Get("filter.lte.logTime"))
+			assert.Equal(t, "assert", q.Get("filter.cnt.message"))
+			assert.Equal(t, "2", q.Get("page.page"))
+			assert.Equal(t, "10", q.Get("page.size"))
+
+			w.Write([]byte(okResponse))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		ti := &TestItem{
+			Id: "id123",
+			client: &Client{
+				Endpoint: s.URL,
+				Project:  "test_project",
+			},
+		}
+
+		f := &LogFilter{
+			Levels: []string{LevelError, LevelWarn},
+			From:   time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Text:   "assert",
+			Page:   2,
+			Size:   10,
+		}
+		lp, err := ti.Logs(f)
+		assert.NoError(t, err)
+		assert.Equal(t, &LogPage{
+			Content: []*LogRecord{
+				{
+					Id:      "log1",
+					ItemId:  "id123",
+					Level:   LevelError,
+					Message: "assertion failed",
+					Time:    time.Date(2019, time.July, 22, 10, 10, 10, 0, time.UTC),
+					BinaryContent: &BinaryContent{
+						Id:          "bin1",
+						ThumbnailId: "thumb1",
+						ContentType: "image/png",
+					},
+				},
+			},
+			Page: &PageInfo{
+				Number:        2,
+				Size:          10,
+				TotalElements: 11,
+				TotalPages:    2,
+			},
+		}, lp)
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		ti := &TestItem{
+			client: &Client{
+				Endpoint: s.URL,
+			},
+		}
+
+		lp, err := ti.QueryLogs(nil)
+		assert.Nil(t, lp)
+		assert.EqualError(t, err, "failed with status 500 Internal Server Error")
+	})
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	t.Run("Successful download", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/test_project/data/bin1", r.URL.Path)
+			assert.Equal(t, "GET", r.Method)
+			w.Write([]byte("screenshot"))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		}
+
+		buf := &bytes.Buffer{}
+		err := c.DownloadAttachment("bin1", buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "screenshot", buf.String())
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+		}
+
+		err := c.DownloadAttachment("bin1", &bytes.Buffer{})
+		assert.EqualError(t, err, "failed with status 404 Not Found")
+	})
+}
+
+func TestParseLevel(t *testing.T) {
+	var levels = []struct {
+		s        string
+		expected Level
+		err      string
+	}{
+		{"error", LevelError, ""},
+		{"WARN", LevelWarn, ""},
+		{"5000", LevelTrace, ""},
+		{"60000", LevelUnknown, ""},
+		{"warning", "", `invalid log level "warning"`},
+		{"12345", "", `invalid log level "12345"`},
+		{"", "", `invalid log level ""`},
+	}
+
+	for _, tt := range levels {
+		l, err := ParseLevel(tt.s)
+		assert.Equal(t, tt.expected, l)
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+
+	assert.True(t, Level(LevelFatal).Valid())
+	assert.False(t, Level("FATAL").Valid())
+	assert.Equal(t, 40000, Level(LevelError).Value())
+}
+
+func TestRegisterLevel(t *testing.T) {
+	l, err := RegisterLevel("Notice", 25000)
+	assert.NoError(t, err)
+	assert.Equal(t, Level("notice"), l)
+	assert.True(t, l.Valid())
+	assert.Equal(t, 25000, l.Value())
+
+	l, err = RegisterLevel("notice", 25000)
+	assert.NoError(t, err)
+	assert.Equal(t, Level("notice"), l)
+
+	parsed, err := ParseLevel("NOTICE")
+	assert.NoError(t, err)
+	assert.Equal(t, l, parsed)
+	parsed, err = ParseLevel("25000")
+	assert.NoError(t, err)
+	assert.Equal(t, l, parsed)
+
+	var invalid = []struct {
+		name  string
+		value int
+		err   string
+	}{
+		{"", 1, "level name is empty"},
+		{"123", 1, `level name "123" is numeric`},
+		{"verbose", 0, `invalid value 0 of level "verbose"`},
+		{"ERROR", 45000, `level "ERROR" is predefined`},
+		{"notice", 26000, `level "notice" is already registered with value 25000`},
+		{"verbose", 30000, `value 30000 is already used by level "warn"`},
+		{"verbose", 25000, `value 25000 is already used by level "notice"`},
+	}
+	for _, tt := range invalid {
+		l, err := RegisterLevel(tt.name, tt.value)
+		assert.Equal(t, Level(""), l)
+		assert.EqualError(t, err, tt.err)
+	}
+	assert.False(t, Level("verbose").Valid())
+}
+
+func TestSendLog(t *testing.T) {
+	t.Run("Successful send", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, `{"launchUuid":"launch123","message":"summary","level":"warn","time":1546300800000}`, string(d))
+			w.WriteHeader(http.StatusCreated)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{Endpoint: s.URL, Project: "test_project"}
+		err := c.SendLog(&LogEntry{
+			Message:  "summary",
+			Level:    "WARN",
+			Time:     time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+			LaunchId: "launch123",
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Custom level", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, `{"item_id":"id123","message":"summary","level":"15000","time":1546300800000}`, string(d))
+			w.WriteHeader(http.StatusCreated)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		l, err := RegisterLevel("config", 15000)
+		assert.NoError(t, err)
+
+		c := &Client{Endpoint: s.URL, Project: "test_project"}
+		err = c.SendLog(&LogEntry{
+			Message: "summary",
+			Level:   l,
+			Time:    time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+			ItemId:  "id123",
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Invalid level", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("log with invalid level must not be sent")
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		ti := &TestItem{
+			Id:     "id123",
+			client: &Client{Endpoint: s.URL, Project: "test_project"},
+		}
+		err := ti.Log("message", "warning", nil)
+		assert.EqualError(t, err, `invalid log level "warning"`)
+	})
+}
@@ -0,0 +1,171 @@
+package rp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// markdownPrefix makes ReportPortal render log message as markdown
+const markdownPrefix = "!!!MARKDOWN_MODE!!!"
+
+// LogMarkdown sends log for specified test item which is rendered as markdown
+func (ti *TestItem) LogMarkdown(message, level string) error {
+	return ti.Log(markdownPrefix+message, level, nil)
+}
+
+// MarkdownTable formats header and rows as markdown table
+func MarkdownTable(header []string, rows [][]string) string {
+	var sb strings.Builder
+	writeRow := func(cells []string) {
+		sb.WriteString("|")
+		for i := range header {
+			var cell string
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			cell = strings.Replace(cell, "|", `\|`, -1)
+			cell = strings.Replace(cell, "\n", "<br>", -1)
+			sb.WriteString(" " + cell + " |")
+		}
+		sb.WriteString("\n")
+	}
+
+	writeRow(header)
+	sb.WriteString("|")
+	for range header {
+		sb.WriteString(" --- |")
+	}
+	sb.WriteString("\n")
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return sb.String()
+}
+
+// MarkdownCode formats code as markdown code block with syntax highlighting for language
+func MarkdownCode(language, code string) string {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fmt.Sprintf("%s%s\n%s\n%s\n", fence, language, strings.TrimSuffix(code, "\n"), fence)
+}
+
+// MarkdownDiff formats line by line difference between expected and actual text as markdown diff block
+func MarkdownDiff(expected, actual string) string {
+	return MarkdownCode("diff", strings.Join(diffLines(strings.Split(expected, "\n"), strings.Split(actual, "\n")), "\n"))
+}
+
+// MarkdownValuesDiff formats difference between expected and actual values marshaled as indented JSON as markdown diff block
+func MarkdownValuesDiff(expected, actual interface{}) string {
+	return MarkdownDiff(formatValue(expected), formatValue(actual))
+}
+
+// formatValue formats value as indented JSON or with Go syntax when it can't be marshaled
+func formatValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%#v", v)
+	}
+	return string(b)
+}
+
+// diffLines returns lines of unified diff without headers based on longest common subsequence of lines.
+// Common prefix and suffix are matched directly and the rest is diffed with Hirschberg's algorithm in linear space
+func diffLines(a, b []string) []string {
+	var prefix, suffix int
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	res := make([]string, 0, len(a)+len(b)-prefix-suffix)
+	for _, line := range a[:prefix] {
+		res = append(res, "  "+line)
+	}
+	res = diffMiddle(res, a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	for _, line := range a[len(a)-suffix:] {
+		res = append(res, "  "+line)
+	}
+	return res
+}
+
+// diffMiddle appends diff of a and b to res splitting a in halves at the point where longest common subsequence crosses
+func diffMiddle(res, a, b []string) []string {
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			res = append(res, "+ "+line)
+		}
+		return res
+	case len(b) == 0:
+		for _, line := range a {
+			res = append(res, "- "+line)
+		}
+		return res
+	case len(a) == 1:
+		for j, line := range b {
+			if line == a[0] {
+				res = diffMiddle(res, nil, b[:j])
+				res = append(res, "  "+line)
+				return diffMiddle(res, nil, b[j+1:])
+			}
+		}
+		res = append(res, "- "+a[0])
+		return diffMiddle(res, nil, b)
+	}
+
+	mid := len(a) / 2
+	head := lcsPrefixLengths(a[:mid], b)
+	tail := lcsSuffixLengths(a[mid:], b)
+	split, best := 0, -1
+	for k := range head {
+		if head[k]+tail[k] > best {
+			split, best = k, head[k]+tail[k]
+		}
+	}
+	res = diffMiddle(res, a[:mid], b[:split])
+	return diffMiddle(res, a[mid:], b[split:])
+}
+
+// lcsPrefixLengths returns lengths of longest common subsequence of a and each prefix b[:j]
+func lcsPrefixLengths(a, b []string) []int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for _, line := range a {
+		for j := 1; j <= len(b); j++ {
+			if line == b[j-1] {
+				cur[j] = prev[j-1] + 1
+			} else if prev[j] >= cur[j-1] {
+				cur[j] = prev[j]
+			} else {
+				cur[j] = cur[j-1]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+// lcsSuffixLengths returns lengths of longest common subsequence of a and each suffix b[j:]
+func lcsSuffixLengths(a, b []string) []int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				cur[j] = prev[j+1] + 1
+			} else if prev[j] >= cur[j+1] {
+				cur[j] = prev[j]
+			} else {
+				cur[j] = cur[j+1]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
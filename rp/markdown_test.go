@@ -0,0 +1,98 @@
+package rp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogMarkdown(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(d), `"message":"!!!MARKDOWN_MODE!!!# Title","level":"info"`)
+		w.WriteHeader(http.StatusCreated)
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	ti := &TestItem{
+		Id: "id123",
+		client: &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		},
+	}
+
+	err := ti.LogMarkdown("# Title", LevelInfo)
+	assert.NoError(t, err)
+}
+
+func TestMarkdownTable(t *testing.T) {
+	table := MarkdownTable([]string{"name", "value"}, [][]string{
+		{"a|b", "multi\nline"},
+		{"short"},
+	})
+	assert.Equal(t, "| name | value |\n| --- | --- |\n| a\\|b | multi<br>line |\n| short |  |\n", table)
+}
+
+func TestMarkdownCode(t *testing.T) {
+	assert.Equal(t, "```go\nfmt.Println()\n```\n", MarkdownCode("go", "fmt.Println()\n"))
+	assert.Equal(t, "````md\n```\n````\n", MarkdownCode("md", "```"))
+}
+
+func TestMarkdownDiff(t *testing.T) {
+	diff := MarkdownDiff("a\nb\nc", "a\nc\nd")
+	assert.Equal(t, "```diff\n  a\n- b\n  c\n+ d\n```\n", diff)
+
+	var diffs = []struct {
+		a, b     string
+		expected []string
+	}{
+		{"", "", []string{"  "}},
+		{"a\nb", "c\nd", []string{"- a", "- b", "+ c", "+ d"}},
+		{"x\na\nb\nc\ny", "x\nb\nc\na\ny", []string{"  x", "- a", "  b", "  c", "+ a", "  y"}},
+		{"a\nb\nc\nd\ne", "b\nx\nd\ne\nf", []string{"- a", "  b", "- c", "+ x", "  d", "  e", "+ f"}},
+	}
+	for _, tt := range diffs {
+		assert.Equal(t, tt.expected, diffLines(strings.Split(tt.a, "\n"), strings.Split(tt.b, "\n")))
+	}
+}
+
+func TestDiffLinesLarge(t *testing.T) {
+	// every third line of expected is changed in actual, table based diff would need 10^8 cells
+	var expected, actual []string
+	for i := 0; i < 10000; i++ {
+		expected = append(expected, fmt.Sprintf("line %d", i))
+		if i%3 == 0 {
+			actual = append(actual, fmt.Sprintf("changed %d", i))
+		} else {
+			actual = append(actual, fmt.Sprintf("line %d", i))
+		}
+	}
+
+	var kept, removed, added []string
+	for _, line := range diffLines(expected, actual) {
+		switch line[:2] {
+		case "  ":
+			kept = append(kept, line[2:])
+		case "- ":
+			removed = append(removed, line[2:])
+		case "+ ":
+			added = append(added, line[2:])
+		}
+	}
+	assert.Len(t, kept, 6666)
+	assert.Len(t, removed, 3334)
+	assert.Len(t, added, 3334)
+}
+
+func TestMarkdownValuesDiff(t *testing.T) {
+	diff := MarkdownValuesDiff(map[string]int{"a": 1, "b": 2}, map[string]int{"a": 1, "b": 3})
+	assert.Equal(t, "```diff\n  {\n    \"a\": 1,\n-   \"b\": 2\n+   \"b\": 3\n  }\n```\n", diff)
+}
@@ -0,0 +1,122 @@
+package rp
+
+// Page defines page of list response
+type Page[T any] struct {
+	Content []T       `json:"content"`
+	Page    *PageInfo `json:"page"`
+}
+
+// last reports whether page with specified number is the last one
+func (p *Page[T]) last(number int) bool {
+	return p.Page == nil || len(p.Content) == 0 || number >= p.Page.TotalPages
+}
+
+// pageResult defines result of page fetch
+type pageResult[T any] struct {
+	page *Page[T]
+	err  error
+}
+
+// Iterator iterates over items of paged list response fetching pages lazily
+type Iterator[T any] struct {
+	fetch    func(number int) (*Page[T], error)
+	prefetch bool
+	page     *Page[T]
+	number   int
+	index    int
+	item     T
+	done     bool
+	err      error
+	pending  chan pageResult[T]
+}
+
+// NewIterator creates iterator over pages returned by fetch, page numbers start from 1.
+// Returns this iterator
+func NewIterator[T any](fetch func(number int) (*Page[T], error)) *Iterator[T] {
+	return &Iterator[T]{fetch: fetch}
+}
+
+// Prefetch enables fetching of next page concurrently with iteration over current one.
+// Returns this iterator
+func (it *Iterator[T]) Prefetch() *Iterator[T] {
+	it.prefetch = true
+	return it
+}
+
+// Next advances iterator to next item fetching next page when needed.
+// Returns false when items are over, iterator is closed or fetch failed
+func (it *Iterator[T]) Next() bool {
+	for it.page == nil || it.index >= len(it.page.Content) {
+		if it.done {
+			return false
+		}
+		it.number++
+		it.page, it.err = it.nextPage()
+		if it.err != nil || it.page == nil {
+			it.done = true
+			return false
+		}
+		it.index = 0
+		it.done = it.page.last(it.number)
+		if it.prefetch && !it.done {
+			it.pending = it.start(it.number + 1)
+		}
+	}
+	it.item = it.page.Content[it.index]
+	it.index++
+	return true
+}
+
+// Item returns current item
+func (it *Iterator[T]) Item() T {
+	return it.item
+}
+
+// Page returns current page
+func (it *Iterator[T]) Page() *Page[T] {
+	return it.page
+}
+
+// Err returns error of page fetch stopped iteration
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Close stops iteration, result of prefetched page is discarded
+func (it *Iterator[T]) Close() {
+	it.done = true
+	it.page = nil
+	it.pending = nil
+}
+
+// nextPage returns prefetched page or fetches it
+func (it *Iterator[T]) nextPage() (*Page[T], error) {
+	if it.pending == nil {
+		return it.fetch(it.number)
+	}
+	r := <-it.pending
+	it.pending = nil
+	return r.page, r.err
+}
+
+// start fetches page in background
+func (it *Iterator[T]) start(number int) chan pageResult[T] {
+	ch := make(chan pageResult[T], 1)
+	go func() {
+		p, err := it.fetch(number)
+		ch <- pageResult[T]{p, err}
+	}()
+	return ch
+}
+
+// Collect collects items of iterator, at most max items when max is positive.
+// Iterator is closed when collecting is finished
+func Collect[T any](it *Iterator[T], max int) ([]T, error) {
+	defer it.Close()
+
+	var items []T
+	for (max <= 0 || len(items) < max) && it.Next() {
+		items = append(items, it.Item())
+	}
+	return items, it.Err()
+}
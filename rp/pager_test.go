@@ -0,0 +1,124 @@
+package rp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pagesFetcher returns fetch function serving pages of items and recording requested numbers
+func pagesFetcher(pages [][]int, mu *sync.Mutex, requested *[]int) func(number int) (*Page[int], error) {
+	return func(number int) (*Page[int], error) {
+		mu.Lock()
+		*requested = append(*requested, number)
+		mu.Unlock()
+		return &Page[int]{
+			Content: pages[number-1],
+			Page:    &PageInfo{Number: number, Size: 2, TotalPages: len(pages)},
+		}, nil
+	}
+}
+
+func TestIterator(t *testing.T) {
+	pages := [][]int{{1, 2}, {3, 4}, {5}}
+
+	t.Run("Iterate all", func(t *testing.T) {
+		var mu sync.Mutex
+		var requested []int
+		it := NewIterator(pagesFetcher(pages, &mu, &requested))
+
+		var items []int
+		for it.Next() {
+			items = append(items, it.Item())
+		}
+		assert.NoError(t, it.Err())
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, items)
+		assert.Equal(t, []int{1, 2, 3}, requested)
+		assert.False(t, it.Next())
+	})
+
+	t.Run("Lazy fetch and early stop", func(t *testing.T) {
+		var mu sync.Mutex
+		var requested []int
+		it := NewIterator(pagesFetcher(pages, &mu, &requested))
+
+		assert.True(t, it.Next())
+		assert.True(t, it.Next())
+		assert.Equal(t, 2, it.Item())
+		assert.Equal(t, []int{1}, requested)
+
+		it.Close()
+		assert.False(t, it.Next())
+		assert.Equal(t, []int{1}, requested)
+	})
+
+	t.Run("Prefetch", func(t *testing.T) {
+		var mu sync.Mutex
+		var requested []int
+		it := NewIterator(pagesFetcher(pages, &mu, &requested)).Prefetch()
+
+		items, err := Collect(it, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, items)
+		assert.Equal(t, []int{1, 2, 3}, requested)
+	})
+
+	t.Run("Collect with cap", func(t *testing.T) {
+		var mu sync.Mutex
+		var requested []int
+		items, err := Collect(NewIterator(pagesFetcher(pages, &mu, &requested)), 3)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, items)
+		assert.Equal(t, []int{1, 2}, requested)
+	})
+
+	t.Run("Single page", func(t *testing.T) {
+		it := NewIterator(func(number int) (*Page[int], error) {
+			assert.Equal(t, 1, number)
+			return &Page[int]{Content: []int{1}}, nil
+		})
+		items, err := Collect(it, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1}, items)
+	})
+
+	t.Run("Fetch error", func(t *testing.T) {
+		it := NewIterator(func(number int) (*Page[int], error) {
+			if number == 2 {
+				return nil, errors.New("failed")
+			}
+			return &Page[int]{Content: []int{1}, Page: &PageInfo{TotalPages: 3}}, nil
+		}).Prefetch()
+		items, err := Collect(it, 0)
+		assert.EqualError(t, err, "failed")
+		assert.Equal(t, []int{1}, items)
+	})
+}
+
+func TestIterLaunches(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/test_project/launch", r.URL.Path)
+		q := r.URL.Query()
+		assert.Equal(t, "smoke", q.Get("filter.cnt.name"))
+		assert.Equal(t, "1", q.Get("page.size"))
+		fmt.Fprintf(w, `{"content":[{"id":"lid%s"}],"page":{"number":%[1]s,"size":1,"totalElements":2,"totalPages":2}}`, q.Get("page.page"))
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := &Client{
+		Endpoint: s.URL,
+		Project:  "test_project",
+	}
+
+	launches, err := Collect(c.IterLaunches(NewQuery().Cnt(FilterFieldName, "smoke").Size(1)), 0)
+	assert.NoError(t, err)
+	assert.Len(t, launches, 2)
+	assert.Equal(t, "lid1", launches[0].Id)
+	assert.Equal(t, "lid2", launches[1].Id)
+}
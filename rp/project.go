@@ -0,0 +1,303 @@
+package rp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefectToInvestigate = "TO_INVESTIGATE"
+	DefectProductBug    = "PRODUCT_BUG"
+	DefectAutomationBug = "AUTOMATION_BUG"
+	DefectSystemIssue   = "SYSTEM_ISSUE"
+	DefectNoDefect      = "NO_DEFECT"
+
+	AnalyzerModeAllLaunches   = "ALL"
+	AnalyzerModeLaunchName    = "LAUNCH_NAME"
+	AnalyzerModeCurrentLaunch = "CURRENT_LAUNCH"
+
+	attrInterruptJobTime = "job.interruptJobTime"
+	attrKeepLogs         = "job.keepLogs"
+	attrKeepScreenshots  = "job.keepScreenshots"
+	attrAutoAnalyzer     = "analyzer.isAutoAnalyzerEnabled"
+	attrAnalyzerMode     = "analyzer.autoAnalyzerMode"
+)
+
+// DefectSubType defines defect subtype of project
+type DefectSubType struct {
+	Id        string `json:"id,omitempty"`
+	Locator   string `json:"locator,omitempty"`
+	TypeRef   string `json:"typeRef"`
+	LongName  string `json:"longName"`
+	ShortName string `json:"shortName"`
+	Color     string `json:"color"`
+}
+
+// ProjectConfiguration defines configuration attributes and defect subtypes of project
+type ProjectConfiguration struct {
+	Attributes map[string]string           `json:"attributes"`
+	SubTypes   map[string][]*DefectSubType `json:"subTypes,omitempty"`
+}
+
+// ProjectResource defines project info
+type ProjectResource struct {
+	Id            int64                 `json:"projectId"`
+	Name          string                `json:"projectName"`
+	Configuration *ProjectConfiguration `json:"configuration"`
+}
+
+// ProjectSettings defines settings of project, durations are stored with precision of seconds.
+// Nil durations, nil AutoAnalyzerEnabled and empty mode mean the setting is not set
+type ProjectSettings struct {
+	InterruptJobTime    *time.Duration
+	KeepLogs            *time.Duration
+	KeepScreenshots     *time.Duration
+	AutoAnalyzerEnabled *bool
+	AutoAnalyzerMode    string
+}
+
+// attributes returns configuration attributes for settings, unset settings are omitted
+func (s *ProjectSettings) attributes() map[string]string {
+	attrs := make(map[string]string)
+	if s.AutoAnalyzerEnabled != nil {
+		attrs[attrAutoAnalyzer] = strconv.FormatBool(*s.AutoAnalyzerEnabled)
+	}
+	durations := map[string]*time.Duration{
+		attrInterruptJobTime: s.InterruptJobTime,
+		attrKeepLogs:         s.KeepLogs,
+		attrKeepScreenshots:  s.KeepScreenshots,
+	}
+	for k, d := range durations {
+		if d != nil {
+			attrs[k] = strconv.FormatInt(int64(*d/time.Second), 10)
+		}
+	}
+	if s.AutoAnalyzerMode != "" {
+		attrs[attrAnalyzerMode] = s.AutoAnalyzerMode
+	}
+	return attrs
+}
+
+// Settings returns settings parsed from configuration attributes of project
+func (p *ProjectResource) Settings() (*ProjectSettings, error) {
+	s := &ProjectSettings{}
+	if p.Configuration == nil {
+		return s, nil
+	}
+	attrs := p.Configuration.Attributes
+
+	durations := map[string]**time.Duration{
+		attrInterruptJobTime: &s.InterruptJobTime,
+		attrKeepLogs:         &s.KeepLogs,
+		attrKeepScreenshots:  &s.KeepScreenshots,
+	}
+	for k, d := range durations {
+		v, ok := attrs[k]
+		if !ok {
+			continue
+		}
+		sec, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of %s", k)
+		}
+		duration := time.Duration(sec) * time.Second
+		*d = &duration
+	}
+
+	if v, ok := attrs[attrAutoAnalyzer]; ok {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of %s", attrAutoAnalyzer)
+		}
+		s.AutoAnalyzerEnabled = &enabled
+	}
+	s.AutoAnalyzerMode = attrs[attrAnalyzerMode]
+	return s, nil
+}
+
+// GetProject gets info of client project
+func (c *Client) GetProject() (*ProjectResource, error) {
+	url := fmt.Sprintf("%s/project/%s", c.endpoint(), c.Project)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't create GET request for %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute GET request for %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	var p *ProjectResource
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response for project")
+	}
+	return p, nil
+}
+
+// GetProjectSettings gets settings of client project
+func (c *Client) GetProjectSettings() (*ProjectSettings, error) {
+	p, err := c.GetProject()
+	if err != nil {
+		return nil, err
+	}
+	return p.Settings()
+}
+
+// UpdateProjectSettings updates settings of client project, unset settings are not changed
+func (c *Client) UpdateProjectSettings(s *ProjectSettings) error {
+	url := fmt.Sprintf("%s/project/%s", c.endpoint(), c.Project)
+	data := struct {
+		Configuration *ProjectConfiguration `json:"configuration"`
+	}{&ProjectConfiguration{Attributes: s.attributes()}}
+
+	b, err := json.Marshal(&data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal object %v", data)
+	}
+
+	r := bytes.NewReader(b)
+	req, err := http.NewRequest(http.MethodPut, url, r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create PUT request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute PUT request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+	return nil
+}
+
+// GetDefectSubTypes gets defect subtypes of project grouped by defect type
+func (c *Client) GetDefectSubTypes() (map[string][]*DefectSubType, error) {
+	url := fmt.Sprintf("%s/%s/settings", c.endpoint(), c.Project)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't create GET request for %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute GET request for %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	var v struct {
+		SubTypes map[string][]*DefectSubType `json:"subTypes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response for project settings")
+	}
+	return v.SubTypes, nil
+}
+
+// CreateDefectSubType creates defect subtype of defect type st.TypeRef. Returns id of created subtype
+func (c *Client) CreateDefectSubType(st *DefectSubType) (string, error) {
+	url := fmt.Sprintf("%s/%s/settings/sub-type", c.endpoint(), c.Project)
+
+	b, err := json.Marshal(st)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to marshal object %v", st)
+	}
+
+	r := bytes.NewReader(b)
+	req, err := http.NewRequest(http.MethodPost, url, r)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create POST request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to execute POST request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	v := struct {
+		Id string `json:"id"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return "", errors.Wrapf(err, "failed to decode response from %s", req.URL)
+	}
+	return v.Id, nil
+}
+
+// UpdateDefectSubTypes updates specified defect subtypes
+func (c *Client) UpdateDefectSubTypes(subTypes ...*DefectSubType) error {
+	url := fmt.Sprintf("%s/%s/settings/sub-type", c.endpoint(), c.Project)
+	data := struct {
+		Ids []*DefectSubType `json:"ids"`
+	}{subTypes}
+
+	b, err := json.Marshal(&data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal object %v", data)
+	}
+
+	r := bytes.NewReader(b)
+	req, err := http.NewRequest(http.MethodPut, url, r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create PUT request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute PUT request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+	return nil
+}
+
+// DeleteDefectSubType deletes defect subtype with specified id
+func (c *Client) DeleteDefectSubType(id string) error {
+	url := fmt.Sprintf("%s/%s/settings/sub-type/%s", c.endpoint(), c.Project, id)
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create DELETE request for %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute DELETE request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+	return nil
+}
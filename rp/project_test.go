@@ -0,0 +1,183 @@
+package rp
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetProjectSettings(t *testing.T) {
+	t.Run("Successful get", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/project/test_project", r.URL.Path)
+			assert.Equal(t, "GET", r.Method)
+
+			w.Write([]byte(`{"projectId":1,"projectName":"test_project","configuration":{"attributes":{` +
+				`"job.interruptJobTime":"86400","job.keepLogs":"7776000","job.keepScreenshots":"1209600",` +
+				`"analyzer.isAutoAnalyzerEnabled":"true","analyzer.autoAnalyzerMode":"LAUNCH_NAME"},` +
+				`"subTypes":{"PRODUCT_BUG":[{"id":"1","locator":"pb001","typeRef":"PRODUCT_BUG","longName":"Product Bug","shortName":"PB","color":"#ec3900"}]}}}`))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		}
+
+		p, err := c.GetProject()
+		assert.NoError(t, err)
+		assert.Equal(t, "PB", p.Configuration.SubTypes[DefectProductBug][0].ShortName)
+
+		ps, err := c.GetProjectSettings()
+		assert.NoError(t, err)
+		enabled := true
+		interruptJobTime, keepLogs, keepScreenshots := 24*time.Hour, 90*24*time.Hour, 14*24*time.Hour
+		assert.Equal(t, &ProjectSettings{
+			InterruptJobTime:    &interruptJobTime,
+			KeepLogs:            &keepLogs,
+			KeepScreenshots:     &keepScreenshots,
+			AutoAnalyzerEnabled: &enabled,
+			AutoAnalyzerMode:    AnalyzerModeLaunchName,
+		}, ps)
+	})
+
+	t.Run("Invalid attribute", func(t *testing.T) {
+		p := &ProjectResource{Configuration: &ProjectConfiguration{
+			Attributes: map[string]string{"job.keepLogs": "forever"},
+		}}
+		_, err := p.Settings()
+		assert.EqualError(t, err, `invalid value of job.keepLogs: strconv.ParseInt: parsing "forever": invalid syntax`)
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+		}
+
+		ps, err := c.GetProjectSettings()
+		assert.Nil(t, ps)
+		assert.EqualError(t, err, "failed with status 404 Not Found")
+	})
+}
+
+func TestUpdateProjectSettings(t *testing.T) {
+	var body string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/project/test_project", r.URL.Path)
+		assert.Equal(t, "PUT", r.Method)
+
+		d, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		body = string(d)
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := &Client{
+		Endpoint: s.URL,
+		Project:  "test_project",
+	}
+
+	t.Run("Only retention", func(t *testing.T) {
+		interruptJobTime, keepLogs := 3*time.Hour, 7*24*time.Hour
+		err := c.UpdateProjectSettings(&ProjectSettings{
+			InterruptJobTime: &interruptJobTime,
+			KeepLogs:         &keepLogs,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"configuration":{"attributes":{"job.interruptJobTime":"10800","job.keepLogs":"604800"}}}`, body)
+		assert.NotContains(t, body, "analyzer.")
+	})
+
+	t.Run("Disable analyzer", func(t *testing.T) {
+		disabled := false
+		err := c.UpdateProjectSettings(&ProjectSettings{AutoAnalyzerEnabled: &disabled})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"configuration":{"attributes":{"analyzer.isAutoAnalyzerEnabled":"false"}}}`, body)
+	})
+}
+
+func TestProjectSettingsRoundTrip(t *testing.T) {
+	var body string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"projectId":1,"projectName":"test_project","configuration":{"attributes":{` +
+				`"job.interruptJobTime":"0","job.keepLogs":"0","analyzer.isAutoAnalyzerEnabled":"false"}}}`))
+			return
+		}
+		d, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		body = string(d)
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := &Client{
+		Endpoint: s.URL,
+		Project:  "test_project",
+	}
+
+	ps, err := c.GetProjectSettings()
+	assert.NoError(t, err)
+	if assert.NotNil(t, ps.KeepLogs) {
+		assert.Equal(t, time.Duration(0), *ps.KeepLogs)
+	}
+	assert.Nil(t, ps.KeepScreenshots)
+
+	assert.NoError(t, c.UpdateProjectSettings(ps))
+	assert.Equal(t, `{"configuration":{"attributes":{"analyzer.isAutoAnalyzerEnabled":"false",`+
+		`"job.interruptJobTime":"0","job.keepLogs":"0"}}}`, body)
+}
+
+func TestDefectSubTypes(t *testing.T) {
+	var requests []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(d))
+
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"subTypes":{"SYSTEM_ISSUE":[{"id":"2","locator":"si001","typeRef":"SYSTEM_ISSUE","longName":"System Issue","shortName":"SI","color":"#0274d1"}]}}`))
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"3"}`))
+		}
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := &Client{
+		Endpoint: s.URL,
+		Project:  "test_project",
+	}
+
+	sts, err := c.GetDefectSubTypes()
+	assert.NoError(t, err)
+	assert.Equal(t, "si001", sts[DefectSystemIssue][0].Locator)
+
+	id, err := c.CreateDefectSubType(&DefectSubType{TypeRef: DefectSystemIssue, LongName: "Network", ShortName: "NET", Color: "#ffa500"})
+	assert.NoError(t, err)
+	assert.Equal(t, "3", id)
+
+	assert.NoError(t, c.UpdateDefectSubTypes(&DefectSubType{Id: "3", TypeRef: DefectSystemIssue, LongName: "Network issue", ShortName: "NET", Color: "#ffa500"}))
+	assert.NoError(t, c.DeleteDefectSubType("3"))
+
+	assert.Equal(t, []string{
+		"GET /test_project/settings ",
+		`POST /test_project/settings/sub-type {"typeRef":"SYSTEM_ISSUE","longName":"Network","shortName":"NET","color":"#ffa500"}`,
+		`PUT /test_project/settings/sub-type {"ids":[{"id":"3","typeRef":"SYSTEM_ISSUE","longName":"Network issue","shortName":"NET","color":"#ffa500"}]}`,
+		"DELETE /test_project/settings/sub-type/3 ",
+	}, requests)
+}
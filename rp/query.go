@@ -0,0 +1,222 @@
+package rp
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Query defines filter, paging and sorting parameters of list requests
+// in ReportPortal syntax: filter.<condition>.<field>, page.page, page.size and page.sort.
+// Methods of nil query return empty parameters, builder methods of nil query return new query
+type Query struct {
+	values url.Values
+	err    error
+}
+
+// multiValueConditions are conditions matching comma separated list of values
+var multiValueConditions = map[string]bool{ConditionIn: true, ConditionHas: true}
+
+// NewQuery creates new empty query. Returns this query
+func NewQuery() *Query {
+	return &Query{values: url.Values{}}
+}
+
+// Where adds filter conditions to query, nil conditions are skipped
+func (q *Query) Where(conditions ...*FilterCondition) *Query {
+	q = q.init()
+	for _, c := range conditions {
+		if c == nil {
+			continue
+		}
+		if err := c.validate(); err != nil {
+			q.setErr(err)
+			continue
+		}
+		values := []string{c.Value}
+		if multiValueConditions[c.Condition] || c.Condition == ConditionBtw {
+			values = strings.Split(c.Value, ",")
+		}
+		q.Filter(c.Condition, c.FilteringField, values...)
+	}
+	return q
+}
+
+// Filter adds condition on field matching values to query. Values are joined with comma
+// and values containing comma make query invalid. Empty values of in and has conditions
+// are skipped, other conditions with empty value are skipped as a whole
+func (q *Query) Filter(condition, field string, values ...string) *Query {
+	q = q.init()
+	var vs []string
+	for _, v := range values {
+		if strings.Contains(v, ",") {
+			q.setErr(errors.Errorf("value %q of %s condition on %s contains comma", v, condition, field))
+			return q
+		}
+		if v == "" {
+			if !multiValueConditions[condition] {
+				return q
+			}
+			continue
+		}
+		vs = append(vs, v)
+	}
+	if len(vs) == 0 {
+		return q
+	}
+	q.values.Add(fmt.Sprintf("filter.%s.%s", condition, field), strings.Join(vs, ","))
+	return q
+}
+
+// Eq adds condition matching field equal to value
+func (q *Query) Eq(field, value string) *Query {
+	return q.Filter(ConditionEq, field, value)
+}
+
+// Cnt adds condition matching field containing value
+func (q *Query) Cnt(field, value string) *Query {
+	return q.Filter(ConditionCnt, field, value)
+}
+
+// Has adds condition matching collection field having all values
+func (q *Query) Has(field string, values ...string) *Query {
+	return q.Filter(ConditionHas, field, values...)
+}
+
+// In adds condition matching field equal to any of values
+func (q *Query) In(field string, values ...string) *Query {
+	return q.Filter(ConditionIn, field, values...)
+}
+
+// Gte adds condition matching field greater than or equal to value
+func (q *Query) Gte(field, value string) *Query {
+	return q.Filter(ConditionGte, field, value)
+}
+
+// Lte adds condition matching field less than or equal to value
+func (q *Query) Lte(field, value string) *Query {
+	return q.Filter(ConditionLte, field, value)
+}
+
+// Btw adds condition matching field between from and to inclusively,
+// condition is skipped when any bound is empty
+func (q *Query) Btw(field, from, to string) *Query {
+	return q.Filter(ConditionBtw, field, from, to)
+}
+
+// Between adds condition matching time field between from and to inclusively.
+// Zero from or to leaves range open on that side
+func (q *Query) Between(field string, from, to time.Time) *Query {
+	q = q.init()
+	switch {
+	case from.IsZero() && to.IsZero():
+		return q
+	case from.IsZero():
+		return q.Lte(field, strconv.FormatInt(toTimestamp(to), 10))
+	case to.IsZero():
+		return q.Gte(field, strconv.FormatInt(toTimestamp(from), 10))
+	}
+	return q.Btw(field, strconv.FormatInt(toTimestamp(from), 10), strconv.FormatInt(toTimestamp(to), 10))
+}
+
+// OrderBy adds sort orders to query
+func (q *Query) OrderBy(orders ...*FilterOrder) *Query {
+	q = q.init()
+	for _, o := range orders {
+		q.Sort(o.SortingColumn, o.IsAsc)
+	}
+	return q
+}
+
+// Sort adds sorting by field to query
+func (q *Query) Sort(field string, asc bool) *Query {
+	q = q.init()
+	direction := "DESC"
+	if asc {
+		direction = "ASC"
+	}
+	q.values.Add("page.sort", field+","+direction)
+	return q
+}
+
+// Page sets number of requested page starting from 1
+func (q *Query) Page(number int) *Query {
+	q = q.init()
+	q.values.Set("page.page", strconv.Itoa(number))
+	return q
+}
+
+// Size sets size of requested page
+func (q *Query) Size(size int) *Query {
+	q = q.init()
+	q.values.Set("page.size", strconv.Itoa(size))
+	return q
+}
+
+// withPage returns copy of query requesting page with specified number
+func (q *Query) withPage(number int) *Query {
+	return (&Query{values: q.Values(), err: q.Err()}).Page(number)
+}
+
+// Err returns first error of building query
+func (q *Query) Err() error {
+	if q == nil {
+		return nil
+	}
+	return q.err
+}
+
+// init returns new query for nil query and initializes values of zero query
+func (q *Query) init() *Query {
+	if q == nil {
+		return NewQuery()
+	}
+	if q.values == nil {
+		q.values = url.Values{}
+	}
+	return q
+}
+
+// setErr keeps first error of building query
+func (q *Query) setErr(err error) {
+	if q.err == nil {
+		q.err = err
+	}
+}
+
+// Values returns copy of query parameters
+func (q *Query) Values() url.Values {
+	v := url.Values{}
+	if q == nil {
+		return v
+	}
+	for k, vs := range q.values {
+		v[k] = append([]string(nil), vs...)
+	}
+	return v
+}
+
+// Encode returns query parameters in URL encoded form
+func (q *Query) Encode() string {
+	return q.Values().Encode()
+}
+
+// listURL returns url of list request with query parameters and additional conditions.
+// Returns error of building query
+func listURL(base string, q *Query, extra url.Values) (string, error) {
+	if err := q.Err(); err != nil {
+		return "", errors.Wrap(err, "invalid query")
+	}
+	v := q.Values()
+	for k, vs := range extra {
+		v[k] = vs
+	}
+	if len(v) == 0 {
+		return base, nil
+	}
+	return base + "?" + v.Encode(), nil
+}
@@ -0,0 +1,120 @@
+package rp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery(t *testing.T) {
+	t.Run("Conditions", func(t *testing.T) {
+		q := NewQuery().
+			Eq(FilterFieldMode, ModeDefault).
+			Cnt(FilterFieldName, "smoke & sanity").
+			Has(FilterFieldAttributes, "os:linux", "ci").
+			In(FilterFieldStatus, StatusFailed, "", StatusInterrupted).
+			Gte(FilterFieldNumber, "5").
+			Lte(FilterFieldNumber, "9").
+			Where(Eq(FilterFieldUser, "admin"))
+		assert.Equal(t, url.Values{
+			"filter.eq.mode":                {"DEFAULT"},
+			"filter.cnt.name":               {"smoke & sanity"},
+			"filter.has.compositeAttribute": {"os:linux,ci"},
+			"filter.in.status":              {"FAILED,INTERRUPTED"},
+			"filter.gte.number":             {"5"},
+			"filter.lte.number":             {"9"},
+			"filter.eq.user":                {"admin"},
+		}, q.Values())
+	})
+
+	t.Run("Empty values are skipped", func(t *testing.T) {
+		q := NewQuery().Eq(FilterFieldName, "").In(FilterFieldStatus)
+		assert.Empty(t, q.Values())
+	})
+
+	t.Run("Time range", func(t *testing.T) {
+		from := time.Unix(1, 0)
+		to := time.Unix(2, 0)
+		assert.Equal(t, "filter.btw.startTime=1000%2C2000", NewQuery().Between(FilterFieldStartTime, from, to).Encode())
+		assert.Equal(t, "filter.gte.startTime=1000", NewQuery().Between(FilterFieldStartTime, from, time.Time{}).Encode())
+		assert.Equal(t, "filter.lte.startTime=2000", NewQuery().Between(FilterFieldStartTime, time.Time{}, to).Encode())
+		assert.Equal(t, "", NewQuery().Between(FilterFieldStartTime, time.Time{}, time.Time{}).Encode())
+	})
+
+	t.Run("Paging and sorting", func(t *testing.T) {
+		q := NewQuery().Page(2).Size(50).Sort(FilterFieldStartTime, false).OrderBy(Asc(FilterFieldName))
+		assert.Equal(t, "page.page=2&page.size=50&page.sort=startTime%2CDESC&page.sort=name%2CASC", q.Encode())
+	})
+
+	t.Run("Escaping", func(t *testing.T) {
+		q := NewQuery().Cnt(FilterFieldName, "a&b=c d")
+		assert.Equal(t, "filter.cnt.name=a%26b%3Dc+d", q.Encode())
+	})
+
+	t.Run("Nil query", func(t *testing.T) {
+		var q *Query
+		assert.Equal(t, "", q.Encode())
+		u, err := listURL("http://rp/launch", q, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "http://rp/launch", u)
+		u, err = listURL("http://rp/log", q, url.Values{"filter.eq.item": {"id"}})
+		assert.NoError(t, err)
+		assert.Equal(t, "http://rp/log?filter.eq.item=id", u)
+	})
+
+	t.Run("Nil and zero query builders", func(t *testing.T) {
+		var q *Query
+		assert.Equal(t, "filter.eq.name=a", q.Eq(FilterFieldName, "a").Encode())
+		assert.Equal(t, "page.page=2", q.Page(2).Encode())
+		assert.Equal(t, "page.sort=name%2CASC", q.OrderBy(Asc(FilterFieldName)).Encode())
+		assert.Equal(t, "filter.eq.user=admin", q.Where(Eq(FilterFieldUser, "admin")).Encode())
+		assert.NotNil(t, q.Between(FilterFieldStartTime, time.Time{}, time.Time{}))
+		assert.NoError(t, q.Err())
+
+		z := &Query{}
+		z.Eq(FilterFieldName, "a").Sort(FilterFieldName, true).Size(10)
+		assert.Equal(t, "filter.eq.name=a&page.size=10&page.sort=name%2CASC", z.Encode())
+	})
+
+	t.Run("Comma in value", func(t *testing.T) {
+		q := NewQuery().In(FilterFieldName, "a", "b,c").Eq(FilterFieldMode, ModeDefault)
+		assert.EqualError(t, q.Err(), `value "b,c" of in condition on name contains comma`)
+		assert.Equal(t, "filter.eq.mode=DEFAULT", q.Encode())
+
+		_, err := listURL("http://rp/launch", q, nil)
+		assert.EqualError(t, err, `invalid query: value "b,c" of in condition on name contains comma`)
+		_, err = listURL("http://rp/launch", q.withPage(2), nil)
+		assert.Error(t, err)
+
+		q = NewQuery().Where(Eq(FilterFieldName, "a,b"), In(FilterFieldStatus, StatusFailed, StatusPassed))
+		assert.EqualError(t, q.Err(), `value "a,b" of eq condition on name contains comma`)
+		assert.Equal(t, "filter.in.status=FAILED%2CPASSED", q.Encode())
+	})
+
+	t.Run("Empty bound", func(t *testing.T) {
+		q := NewQuery().Btw(FilterFieldNumber, "", "5").Btw(FilterFieldNumber, "1", "")
+		assert.Empty(t, q.Values())
+		assert.NoError(t, q.Err())
+
+		q = NewQuery().Where(Btw(FilterFieldNumber, "", "5"))
+		assert.EqualError(t, q.Err(), `btw condition on number requires both bounds, got ",5"`)
+		assert.Empty(t, q.Values())
+
+		q = NewQuery().Where(BtwTime(FilterFieldStartTime, time.Time{}, time.Unix(2, 0)))
+		assert.NoError(t, q.Err())
+		assert.Equal(t, "filter.lte.startTime=2000", q.Encode())
+
+		q = NewQuery().Where(nil, Btw(FilterFieldNumber, "1", "5"))
+		assert.NoError(t, q.Err())
+		assert.Equal(t, "filter.btw.number=1%2C5", q.Encode())
+	})
+
+	t.Run("Values are copied", func(t *testing.T) {
+		q := NewQuery().Eq(FilterFieldName, "a")
+		v := q.Values()
+		v.Set("filter.eq.name", "b")
+		assert.Equal(t, "a", q.Values().Get("filter.eq.name"))
+	})
+}
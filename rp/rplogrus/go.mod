@@ -0,0 +1,17 @@
+module github.com/igorexec/client-go/rp/rplogrus
+
+go 1.18
+
+require (
+	github.com/igorexec/client-go v0.0.0-20261019013623-84cc887bcc17
+	github.com/sirupsen/logrus v1.9.3
+	github.com/stretchr/testify v1.8.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pkg/errors v0.8.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,69 @@
+// Package rplogrus provides logrus hook which sends log entries to ReportPortal
+package rplogrus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/igorexec/client-go/rp"
+	"github.com/sirupsen/logrus"
+)
+
+// Hook defines logrus hook which sends entries as logs of test item
+type Hook struct {
+	item   *rp.TestItem
+	levels []logrus.Level
+}
+
+// NewHook creates new hook which sends entries with level or higher to test item
+func NewHook(item *rp.TestItem, level logrus.Level) *Hook {
+	var levels []logrus.Level
+	for _, l := range logrus.AllLevels {
+		if l <= level {
+			levels = append(levels, l)
+		}
+	}
+	return &Hook{item, levels}
+}
+
+// Levels returns levels for which hook is fired
+func (h *Hook) Levels() []logrus.Level {
+	return h.levels
+}
+
+// Fire sends entry with its fields rendered into message
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(entry.Message)
+	for _, k := range keys {
+		fmt.Fprintf(&sb, " %s=%v", k, entry.Data[k])
+	}
+	return h.item.Log(sb.String(), level(entry.Level), nil)
+}
+
+// level maps logrus level to ReportPortal log level
+func level(l logrus.Level) string {
+	switch l {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return rp.LevelFatal
+	case logrus.ErrorLevel:
+		return rp.LevelError
+	case logrus.WarnLevel:
+		return rp.LevelWarn
+	case logrus.InfoLevel:
+		return rp.LevelInfo
+	case logrus.DebugLevel:
+		return rp.LevelDebug
+	case logrus.TraceLevel:
+		return rp.LevelTrace
+	default:
+		return rp.LevelUnknown
+	}
+}
@@ -0,0 +1,48 @@
+package rplogrus
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/igorexec/client-go/rp"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHook(t *testing.T) {
+	var logs []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		if r.URL.Path == "/api/v1/test_project/log" {
+			logs = append(logs, string(d))
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": "id123"}`))
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := rp.NewClient(s.URL, "test_project", "token", 1)
+	ti := rp.NewTestItem(rp.NewLaunch(c, "", "", rp.ModeDefault, nil), "test", "", rp.TestItemTest, nil, nil)
+	assert.NoError(t, ti.Start())
+
+	l := logrus.New()
+	l.SetOutput(ioutil.Discard)
+	l.SetLevel(logrus.TraceLevel)
+	l.AddHook(NewHook(ti, logrus.InfoLevel))
+
+	l.Debug("hidden")
+	l.WithFields(logrus.Fields{"user": "bob", "attempt": 2}).Warn("logged in")
+
+	assert.Len(t, logs, 1)
+	assert.Contains(t, logs[0], `"message":"logged in attempt=2 user=bob","level":"warn"`)
+}
+
+func TestLevel(t *testing.T) {
+	assert.Equal(t, rp.LevelFatal, level(logrus.PanicLevel))
+	assert.Equal(t, rp.LevelError, level(logrus.ErrorLevel))
+	assert.Equal(t, rp.LevelTrace, level(logrus.TraceLevel))
+}
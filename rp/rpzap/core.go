@@ -0,0 +1,88 @@
+// Package rpzap provides zap core which sends log entries to ReportPortal
+package rpzap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/igorexec/client-go/rp"
+	"go.uber.org/zap/zapcore"
+)
+
+// Core defines zapcore.Core which sends entries as logs of test item
+type Core struct {
+	zapcore.LevelEnabler
+
+	item   *rp.TestItem
+	fields []zapcore.Field
+}
+
+// NewCore creates new core which sends entries enabled by enab to test item
+func NewCore(item *rp.TestItem, enab zapcore.LevelEnabler) *Core {
+	return &Core{LevelEnabler: enab, item: item}
+}
+
+// With returns core which adds fields to every entry
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	c2 := *c
+	c2.fields = make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	c2.fields = append(c2.fields, c.fields...)
+	c2.fields = append(c2.fields, fields...)
+	return &c2
+}
+
+// Check adds core to checked entry when entry level is enabled
+func (c *Core) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+// Write sends entry with its fields rendered into message
+func (c *Core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	keys := make([]string, 0, len(enc.Fields))
+	for k := range enc.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(entry.Message)
+	for _, k := range keys {
+		fmt.Fprintf(&sb, " %s=%v", k, enc.Fields[k])
+	}
+	return c.item.Log(sb.String(), level(entry.Level), nil)
+}
+
+// Sync does nothing, logs are sent on write
+func (c *Core) Sync() error {
+	return nil
+}
+
+// level maps zap level to ReportPortal log level
+func level(l zapcore.Level) string {
+	switch l {
+	case zapcore.DebugLevel:
+		return rp.LevelDebug
+	case zapcore.InfoLevel:
+		return rp.LevelInfo
+	case zapcore.WarnLevel:
+		return rp.LevelWarn
+	case zapcore.ErrorLevel:
+		return rp.LevelError
+	case zapcore.DPanicLevel, zapcore.PanicLevel, zapcore.FatalLevel:
+		return rp.LevelFatal
+	default:
+		return rp.LevelUnknown
+	}
+}
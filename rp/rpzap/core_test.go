@@ -0,0 +1,45 @@
+package rpzap
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/igorexec/client-go/rp"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestCore(t *testing.T) {
+	var logs []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		if r.URL.Path == "/api/v1/test_project/log" {
+			logs = append(logs, string(d))
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": "id123"}`))
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := rp.NewClient(s.URL, "test_project", "token", 1)
+	ti := rp.NewTestItem(rp.NewLaunch(c, "", "", rp.ModeDefault, nil), "test", "", rp.TestItemTest, nil, nil)
+	assert.NoError(t, ti.Start())
+
+	l := zap.New(NewCore(ti, zapcore.InfoLevel)).With(zap.String("user", "bob"))
+	l.Debug("hidden")
+	l.Error("logged in", zap.Int("attempt", 2))
+
+	assert.Len(t, logs, 1)
+	assert.Contains(t, logs[0], `"message":"logged in attempt=2 user=bob","level":"error"`)
+}
+
+func TestLevel(t *testing.T) {
+	assert.Equal(t, rp.LevelDebug, level(zapcore.DebugLevel))
+	assert.Equal(t, rp.LevelWarn, level(zapcore.WarnLevel))
+	assert.Equal(t, rp.LevelFatal, level(zapcore.DPanicLevel))
+}
@@ -0,0 +1,17 @@
+module github.com/igorexec/client-go/rp/rpzap
+
+go 1.19
+
+require (
+	github.com/igorexec/client-go v0.0.0-20261019013623-84cc887bcc17
+	github.com/stretchr/testify v1.8.1
+	go.uber.org/zap v1.27.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pkg/errors v0.8.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,247 @@
+package rp
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// minimal versions of API service supporting features
+	minAttributesVersion  = "5.0"
+	minAsyncAPIVersion    = "5.0"
+	minNestedStepsVersion = "5.1"
+
+	ServiceAPI      = "api"
+	ServiceUI       = "ui"
+	ServiceUAT      = "uat"
+	ServiceAnalyzer = "analyzer"
+	ServiceJobs     = "jobs"
+)
+
+// BuildInfo defines build of ReportPortal service
+type BuildInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Branch  string `json:"branch"`
+	Repo    string `json:"repo"`
+}
+
+// ServiceInfo defines info of ReportPortal service
+type ServiceInfo struct {
+	Build          *BuildInfo      `json:"build"`
+	Extensions     json.RawMessage `json:"extensions,omitempty"`
+	AuthExtensions json.RawMessage `json:"authExtensions,omitempty"`
+}
+
+// Capabilities defines features supported by server
+type Capabilities struct {
+	// Attributes are key-value attributes of launches and test items replacing tags
+	Attributes bool
+	// AsyncAPI is asynchronous reporting API available at /api/v2
+	AsyncAPI bool
+	// NestedSteps are test items reported without statistics
+	NestedSteps bool
+	// AutoAnalysis is analysis of failures by analyzer service
+	AutoAnalysis bool
+}
+
+// ServerInfo defines services of ReportPortal server keyed by name and health of server
+type ServerInfo struct {
+	Services map[string]*ServiceInfo
+	Health   string
+}
+
+// Version returns build version of service or empty string when service is unknown
+func (si *ServerInfo) Version(service string) string {
+	s, ok := si.Services[service]
+	if !ok || s.Build == nil {
+		return ""
+	}
+	return s.Build.Version
+}
+
+// MajorVersion returns major version of API service or 0 when it's unknown
+func (si *ServerInfo) MajorVersion() int {
+	return versionParts(si.Version(ServiceAPI))[0]
+}
+
+// Capabilities returns features supported by server according to build version
+// of API service and services reported by server
+func (si *ServerInfo) Capabilities() *Capabilities {
+	api := si.Version(ServiceAPI)
+	return &Capabilities{
+		Attributes:   versionAtLeast(api, minAttributesVersion),
+		AsyncAPI:     versionAtLeast(api, minAsyncAPIVersion),
+		NestedSteps:  versionAtLeast(api, minNestedStepsVersion),
+		AutoAnalysis: si.Version(ServiceAnalyzer) != "",
+	}
+}
+
+// versionParts returns major, minor and patch numbers of version, suffixes like -SNAPSHOT
+// are ignored and missing or invalid parts are 0
+func versionParts(version string) [3]int {
+	var parts [3]int
+	for i, p := range strings.SplitN(version, ".", 3) {
+		end := strings.IndexFunc(p, func(r rune) bool { return r < '0' || r > '9' })
+		if end >= 0 {
+			p = p[:end]
+		}
+		parts[i], _ = strconv.Atoi(p)
+	}
+	return parts
+}
+
+// versionAtLeast reports whether version is known and not lower than min
+func versionAtLeast(version, min string) bool {
+	v := versionParts(version)
+	m := versionParts(min)
+	if v[0] == 0 {
+		return false
+	}
+	for i := range v {
+		if v[i] != m[i] {
+			return v[i] > m[i]
+		}
+	}
+	return true
+}
+
+// Extensions returns sorted names of extensions enabled in services.
+// Extensions listed as array contribute their values and listed as object contribute their keys
+func (si *ServerInfo) Extensions() []string {
+	var names []string
+	for _, s := range si.Services {
+		names = append(names, extensionNames(s.Extensions)...)
+		names = append(names, extensionNames(s.AuthExtensions)...)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// extensionNames returns names of extensions in raw list
+func extensionNames(raw json.RawMessage) []string {
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	return names
+}
+
+// ServerInfo gets versions of services and health of server from info and health endpoints of gateway.
+// Info of API service is used when gateway info isn't available
+func (c *Client) ServerInfo() (*ServerInfo, error) {
+	root := serverRoot(c.Endpoint)
+
+	var services map[string]*ServiceInfo
+	if _, err := c.getInfo(root+"/composite/info", &services); err != nil {
+		var api *ServiceInfo
+		if _, apiErr := c.getInfo(root+"/api/info", &api); apiErr != nil {
+			return nil, errors.Wrapf(err, "failed to get server info (API info: %v)", apiErr)
+		}
+		if api == nil {
+			return nil, errors.Wrap(err, "failed to get server info (API info is empty)")
+		}
+		services = map[string]*ServiceInfo{ServiceAPI: api}
+	}
+	si := &ServerInfo{Services: services}
+
+	// health endpoint responds with status of server and 503 when it's down
+	var health struct {
+		Status string `json:"status"`
+	}
+	code, _ := c.getInfo(root+"/composite/health", &health)
+	if code == http.StatusOK || code == http.StatusServiceUnavailable {
+		si.Health = health.Status
+	}
+	return si, nil
+}
+
+// DetectServer gets server info and configures client for it: ServerVersion is set to major
+// version of API, v5 features are enabled according to server capabilities and endpoint of
+// async API v2 is replaced with v1 when server doesn't support it.
+// DetectServer changes Endpoint and must be called before client is shared between goroutines
+func (c *Client) DetectServer() (*ServerInfo, error) {
+	si, err := c.ServerInfo()
+	if err != nil {
+		return nil, err
+	}
+	major := si.MajorVersion()
+	if major == 0 {
+		return si, errors.Errorf("unknown version %q of server API", si.Version(ServiceAPI))
+	}
+
+	c.ServerVersion = major
+	c.capabilities = si.Capabilities()
+	if !c.capabilities.AsyncAPI {
+		c.Endpoint = strings.Replace(c.Endpoint, "/api/v2", "/api/v1", 1)
+	}
+	return si, nil
+}
+
+// detectServer detects server of client created by NewClient once unless ServerVersion is set.
+// Concurrent callers wait for detection, so endpoint and capabilities aren't changed after it returns
+func (c *Client) detectServer() {
+	if !c.detect {
+		return
+	}
+	c.detectOnce.Do(func() {
+		if c.ServerVersion == 0 && c.capabilities == nil {
+			c.DetectServer()
+		}
+	})
+}
+
+// endpoint returns endpoint for API requests, server is detected before the first request
+// so that endpoint is switched to v1 API before it's used
+func (c *Client) endpoint() string {
+	c.detectServer()
+	return c.Endpoint
+}
+
+// features returns features supported by server detected by detectServer,
+// v4 formats are used when detection fails
+func (c *Client) features() *Capabilities {
+	c.detectServer()
+	if c.capabilities != nil {
+		return c.capabilities
+	}
+	v5 := c.ServerVersion >= 5
+	return &Capabilities{Attributes: v5, AsyncAPI: v5, NestedSteps: v5}
+}
+
+// getInfo decodes response of info endpoint to v. Returns status code of response,
+// response is decoded for any status but error is returned for statuses other than 200
+func (c *Client) getInfo(url string, v interface{}) (int, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return 0, errors.Wrapf(err, "can't create GET request for %s", url)
+	}
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to execute GET request for %s", req.URL)
+	}
+	defer resp.Body.Close()
+
+	decodeErr := json.NewDecoder(resp.Body).Decode(v)
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, errors.Errorf("failed with status %s", resp.Status)
+	}
+	if decodeErr != nil {
+		return resp.StatusCode, errors.Wrapf(decodeErr, "failed to decode response from %s", req.URL)
+	}
+	return resp.StatusCode, nil
+}
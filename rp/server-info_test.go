@@ -0,0 +1,218 @@
+package rp
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerInfo(t *testing.T) {
+	t.Run("Composite info", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "GET", r.Method)
+			switch r.URL.Path {
+			case "/composite/info":
+				w.Write([]byte(`{` +
+					`"api":{"build":{"name":"API Service","version":"5.7.2"},"extensions":["rp-plugin-jira"]},` +
+					`"ui":{"build":{"name":"Service UI","version":"5.7.0"}},` +
+					`"uat":{"build":{"version":"5.7.1"},"authExtensions":{"github":{},"ldap":{}}},` +
+					`"jobs":{"build":{"version":"5.7.0"}},` +
+					`"analyzer":{"build":{"version":"5.7.3"}}}`))
+			case "/composite/health":
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(`{"status":"DOWN"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := NewClient(s.URL, "test_project", "token", 2)
+		si, err := c.DetectServer()
+		assert.NoError(t, err)
+		assert.Equal(t, "5.7.2", si.Version(ServiceAPI))
+		assert.Equal(t, "5.7.0", si.Version(ServiceUI))
+		assert.Equal(t, "5.7.3", si.Version(ServiceAnalyzer))
+		assert.Equal(t, "5.7.0", si.Version(ServiceJobs))
+		assert.Equal(t, "", si.Version("index"))
+		assert.Equal(t, []string{"github", "ldap", "rp-plugin-jira"}, si.Extensions())
+		assert.Equal(t, "DOWN", si.Health)
+		assert.Equal(t, &Capabilities{Attributes: true, AsyncAPI: true, NestedSteps: true, AutoAnalysis: true}, si.Capabilities())
+
+		assert.Equal(t, 5, c.ServerVersion)
+		assert.Equal(t, s.URL+"/api/v2", c.Endpoint)
+	})
+
+	t.Run("API info fallback", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/info" {
+				w.Write([]byte(`{"build":{"version":"4.3.0"}}`))
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := NewClient(s.URL, "test_project", "token", 2)
+		c.ServerVersion = 5
+		si, err := c.DetectServer()
+		assert.NoError(t, err)
+		assert.Equal(t, "", si.Health)
+		assert.Equal(t, &Capabilities{}, si.Capabilities())
+
+		assert.Equal(t, 4, c.ServerVersion)
+		assert.Equal(t, s.URL+"/api/v1", c.Endpoint)
+	})
+
+	t.Run("Unknown version", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"api":{}}`))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := NewClient(s.URL, "test_project", "token", 1)
+		_, err := c.DetectServer()
+		assert.EqualError(t, err, `unknown version "" of server API`)
+		assert.Equal(t, 0, c.ServerVersion)
+	})
+
+	t.Run("Unavailable", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := NewClient(s.URL, "test_project", "token", 1)
+		si, err := c.ServerInfo()
+		assert.Nil(t, si)
+		assert.EqualError(t, err, "failed to get server info (API info: failed with status 502 Bad Gateway): "+
+			"failed with status 502 Bad Gateway")
+	})
+
+	t.Run("Empty API info", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/info" {
+				w.Write([]byte(`null`))
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := NewClient(s.URL, "test_project", "token", 1)
+		si, err := c.ServerInfo()
+		assert.Nil(t, si)
+		assert.EqualError(t, err, "failed to get server info (API info is empty): failed with status 404 Not Found")
+	})
+}
+
+func TestCapabilities(t *testing.T) {
+	cases := []struct {
+		version string
+		want    Capabilities
+	}{
+		{"", Capabilities{}},
+		{"develop", Capabilities{}},
+		{"4.3.0", Capabilities{}},
+		{"5.0.0", Capabilities{Attributes: true, AsyncAPI: true}},
+		{"5.0-SNAPSHOT", Capabilities{Attributes: true, AsyncAPI: true}},
+		{"5.1.0-RC1", Capabilities{Attributes: true, AsyncAPI: true, NestedSteps: true}},
+		{"5.11", Capabilities{Attributes: true, AsyncAPI: true, NestedSteps: true}},
+	}
+	for _, tc := range cases {
+		si := &ServerInfo{Services: map[string]*ServiceInfo{ServiceAPI: {Build: &BuildInfo{Version: tc.version}}}}
+		assert.Equal(t, &tc.want, si.Capabilities(), tc.version)
+	}
+}
+
+func TestDetectOnFirstUse(t *testing.T) {
+	var infos int
+	var body string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/composite/info":
+			infos++
+			w.Write([]byte(`{"api":{"build":{"version":"5.7.2"}}}`))
+		case "/api/v2/test_project/launch":
+			b, _ := ioutil.ReadAll(r.Body)
+			body = string(b)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"l1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := NewClient(s.URL, "test_project", "token", 2)
+	for i := 0; i < 2; i++ {
+		l := NewLaunch(c, "launch", "", ModeDefault, []string{"os:linux"})
+		assert.NoError(t, l.Start())
+	}
+	assert.Equal(t, 1, infos)
+	assert.Equal(t, 5, c.ServerVersion)
+	assert.Contains(t, body, `"attributes":[{"key":"os","value":"linux"}]`)
+
+	t.Run("Version set", func(t *testing.T) {
+		infos = 0
+		c := NewClient(s.URL, "test_project", "token", 2)
+		c.ServerVersion = 4
+		l := NewLaunch(c, "launch", "", ModeDefault, []string{"os:linux"})
+		assert.NoError(t, l.Start())
+		assert.Equal(t, 0, infos)
+		assert.Contains(t, body, `"tags":["os:linux"]`)
+	})
+
+	t.Run("Server without async API", func(t *testing.T) {
+		var mu sync.Mutex
+		var requests []string
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			mu.Unlock()
+			switch r.URL.Path {
+			case "/composite/info":
+				w.Write([]byte(`{"api":{"build":{"version":"4.3.0"}}}`))
+			case "/api/v1/test_project/launch":
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id":"l1"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := NewClient(s.URL, "test_project", "token", 2)
+		r := NewAsyncReporter(4)
+		for i := 0; i < 4; i++ {
+			r.StartLaunch(NewLaunch(c, "launch", "", ModeDefault, nil))
+		}
+		assert.NoError(t, r.Close())
+		assert.Equal(t, 4, c.ServerVersion)
+		assert.Equal(t, []string{
+			"GET /composite/info",
+			"GET /composite/health",
+			"POST /api/v1/test_project/launch",
+			"POST /api/v1/test_project/launch",
+			"POST /api/v1/test_project/launch",
+			"POST /api/v1/test_project/launch",
+		}, requests)
+	})
+}
+
+func TestTagsToAttributes(t *testing.T) {
+	assert.Nil(t, tagsToAttributes(nil))
+	assert.Equal(t, []*Attribute{{"os", "linux"}, {Value: "smoke"}, {Value: ":x"}, {"url", "http://host"}},
+		tagsToAttributes([]string{"os:linux", "smoke", ":x", "url:http://host"}))
+}
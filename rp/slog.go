@@ -0,0 +1,111 @@
+//go:build go1.21
+
+package rp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// SlogHandler defines slog.Handler which sends records as logs of test item
+type SlogHandler struct {
+	item   *TestItem
+	level  slog.Leveler
+	attrs  []slog.Attr
+	groups []string
+}
+
+// NewSlogHandler creates new handler which sends records with level or higher to test item.
+// Nil level means slog.LevelInfo
+func NewSlogHandler(item *TestItem, level slog.Leveler) *SlogHandler {
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	return &SlogHandler{item: item, level: level}
+}
+
+// Enabled reports whether handler sends records with specified level
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle sends record with its attributes rendered into message
+func (h *SlogHandler) Handle(_ context.Context, r slog.Record) error {
+	var sb strings.Builder
+	sb.WriteString(r.Message)
+	for _, a := range h.attrs {
+		writeSlogAttr(&sb, "", a)
+	}
+	prefix := strings.Join(h.groups, ".")
+	r.Attrs(func(a slog.Attr) bool {
+		writeSlogAttr(&sb, prefix, a)
+		return true
+	})
+	return h.item.Log(sb.String(), slogLevel(r.Level), nil)
+}
+
+// WithAttrs returns handler which adds attrs to every record
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	h2.attrs = append(h2.attrs, h.attrs...)
+	prefix := strings.Join(h.groups, ".")
+	for _, a := range attrs {
+		if prefix != "" {
+			a.Key = prefix + "." + a.Key
+		}
+		h2.attrs = append(h2.attrs, a)
+	}
+	return &h2
+}
+
+// WithGroup returns handler which qualifies keys of following attributes with group name
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(append([]string{}, h.groups...), name)
+	return &h2
+}
+
+// writeSlogAttr writes attribute as key=value, group attributes are flattened with dotted keys
+func writeSlogAttr(sb *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	key := a.Key
+	if prefix != "" && key != "" {
+		key = prefix + "." + key
+	} else if key == "" {
+		key = prefix
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			writeSlogAttr(sb, key, ga)
+		}
+		return
+	}
+	fmt.Fprintf(sb, " %s=%v", key, a.Value.Any())
+}
+
+// slogLevel maps slog level to ReportPortal log level
+func slogLevel(level slog.Level) string {
+	switch {
+	case level < slog.LevelDebug:
+		return LevelTrace
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarn
+	default:
+		return LevelError
+	}
+}
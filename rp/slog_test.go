@@ -0,0 +1,50 @@
+//go:build go1.21
+
+package rp
+
+import (
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var logs []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		logs = append(logs, string(d))
+		w.WriteHeader(http.StatusCreated)
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	ti := &TestItem{
+		Id: "id123",
+		client: &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		},
+	}
+
+	l := slog.New(NewSlogHandler(ti, slog.LevelInfo)).With("user", "bob")
+	l.Debug("hidden")
+	l.Info("logged in", "attempt", 2)
+	l.WithGroup("req").Error("failed", "status", 500, slog.Group("headers", "accept", "json"))
+
+	assert.Len(t, logs, 2)
+	assert.Contains(t, logs[0], `"message":"logged in user=bob attempt=2","level":"info"`)
+	assert.Contains(t, logs[1], `"message":"failed user=bob req.status=500 req.headers.accept=json","level":"error"`)
+}
+
+func TestSlogLevel(t *testing.T) {
+	assert.Equal(t, LevelTrace, slogLevel(slog.LevelDebug-4))
+	assert.Equal(t, LevelDebug, slogLevel(slog.LevelDebug))
+	assert.Equal(t, LevelInfo, slogLevel(slog.LevelInfo))
+	assert.Equal(t, LevelWarn, slogLevel(slog.LevelWarn))
+	assert.Equal(t, LevelError, slogLevel(slog.LevelError+4))
+}
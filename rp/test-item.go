@@ -4,10 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
-	"mime/multipart"
 	"net/http"
-	"net/textproto"
+	"runtime/debug"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -46,29 +45,24 @@ type TestItem struct {
 	Tags      []string
 	Type      string
 
-	client *Client
-	launch *Launch
-}
-
-// Attachment defines attachment for log request with file
-type Attachment struct {
-	Name     string
-	Data     io.Reader
-	MimeType string
-}
+	client     *Client
+	launch     *Launch
+	nested     bool
+	fixtureErr error
 
-// fileInfo defines file structure for json request part
-type fileInfo struct {
-	Name string `json:"name"`
+	mu       sync.Mutex
+	status   string
+	children []*TestItem
+	finished rollup
 }
 
-// jsonRequestPart defines request object for request with attachment
-type jsonRequestPart []struct {
-	File    *fileInfo `json:"file"`
-	ItemId  string    `json:"item_id"`
-	Level   string    `json:"level"`
-	Message string    `json:"message"`
-	Time    int64     `json:"time"`
+// ExternalIssue defines ticket in external bug tracking system
+type ExternalIssue struct {
+	TicketId   string
+	Url        string
+	BtsProject string
+	BtsUrl     string
+	SubmitDate time.Time
 }
 
 // NewTestItem creates new test item
@@ -88,14 +82,14 @@ func NewTestItem(launch *Launch, name, description, itemType string, tags []stri
 func (ti *TestItem) Start() error {
 	var url string
 	if ti.Parent != nil {
-		url = fmt.Sprintf("%s/%s/item/%s", ti.client.Endpoint, ti.client.Project, ti.Parent.Id)
+		url = fmt.Sprintf("%s/%s/item/%s", ti.client.endpoint(), ti.client.Project, ti.Parent.Id)
 	} else {
-		url = fmt.Sprintf("%s/%s/item", ti.client.Endpoint, ti.client.Project)
+		url = fmt.Sprintf("%s/%s/item", ti.client.endpoint(), ti.client.Project)
 	}
 	data := struct {
 		Name        string   `json:"name"`
 		Description string   `json:"description"`
-		Tags        []string `json:"tags"`
+		Tags        []string `json:"tags,omitempty"`
 		StartTime   int64    `json:"start_time"`
 		LaunchId    string   `json:"launch_id"`
 		Type        string   `json:"type"`
@@ -103,14 +97,24 @@ func (ti *TestItem) Start() error {
 			Key   string `json:"key"`
 			Value string `json:"value"`
 		} `json:"parameters"`
+		Attributes []*Attribute `json:"attributes,omitempty"`
+		HasStats   *bool        `json:"hasStats,omitempty"`
 	}{
 		Name:        ti.Name,
 		Description: ti.Description,
-		Tags:        ti.Tags,
 		StartTime:   toTimestamp(time.Now()),
 		LaunchId:    ti.launch.Id,
 		Type:        ti.Type,
 	}
+	if ti.client.features().Attributes {
+		data.Attributes = tagsToAttributes(ti.Tags)
+	} else {
+		data.Tags = ti.Tags
+	}
+	if ti.nested && ti.client.features().NestedSteps {
+		hasStats := false
+		data.HasStats = &hasStats
+	}
 
 	b, err := json.Marshal(&data)
 	if err != nil {
@@ -126,10 +130,10 @@ func (ti *TestItem) Start() error {
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := doRequest(req, ti.client.Token)
-	defer resp.Body.Close()
 	if err != nil {
 		return errors.Wrapf(err, "failed to execute POST request %s", req.URL)
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusCreated {
 		return errors.Errorf("failed with status %s", resp.Status)
 	}
@@ -141,12 +145,30 @@ func (ti *TestItem) Start() error {
 		return errors.Wrapf(err, "failed to decode response from %s", req.URL)
 	}
 	ti.Id = v.Id
+
+	if ti.Parent != nil {
+		ti.Parent.addChild(ti)
+	} else if ti.launch != nil {
+		ti.launch.addItem(ti)
+	}
 	return nil
 }
 
-// Finish finishes specified test item
+// Finish finishes specified test item.
+// When status is empty and client has StatusRollup enabled, status is derived from child items
 func (ti *TestItem) Finish(status string) error {
-	url := fmt.Sprintf("%s/%s/item/%s", ti.client.Endpoint, ti.client.Project, ti.Id)
+	var flushErr error
+	if ti.client.LogBatcher != nil {
+		flushErr = ti.client.LogBatcher.FlushItem(ti.Id)
+	}
+
+	if status == "" && ti.client.StatusRollup {
+		ti.mu.Lock()
+		status = rollupStatus(ti.finished, ti.children)
+		ti.mu.Unlock()
+	}
+
+	url := fmt.Sprintf("%s/%s/item/%s", ti.client.endpoint(), ti.client.Project, ti.Id)
 	data := struct {
 		EndTime int64  `json:"end_time"`
 		Status  string `json:"status"`
@@ -166,47 +188,61 @@ func (ti *TestItem) Finish(status string) error {
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := doRequest(req, ti.client.Token)
-	defer resp.Body.Close()
 	if err != nil {
 		return errors.Wrapf(err, "failed to execute PUT request to %s", req.URL)
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		return errors.Errorf("failed with status %s", resp.Status)
 	}
-	return nil
-}
 
-// Log sends log for specified test item
-func (ti *TestItem) Log(message, level string, attachment *Attachment) error {
-	var req *http.Request
-	var err error
-	if attachment != nil {
-		req, err = ti.getReqForLogWithAttach(message, level, attachment)
-	} else {
-		req, err = ti.getReqForLog(message, level)
-	}
-	if err != nil {
-		return err
-	}
+	ti.mu.Lock()
+	ti.status = status
+	ti.mu.Unlock()
 
-	resp, err := doRequest(req, ti.client.Token)
-	defer resp.Body.Close()
-	if err != nil {
-		return errors.Wrapf(err, "failed to execute POST request %s", req.URL)
+	if ti.Parent != nil {
+		ti.Parent.removeChild(ti, status)
+	} else if ti.launch != nil {
+		ti.launch.removeItem(ti, status)
 	}
-	if resp.StatusCode != http.StatusCreated {
-		return errors.Errorf("failed with status %s", resp.Status)
+
+	if flushErr != nil {
+		return errors.Wrap(flushErr, "failed to flush logs")
 	}
 	return nil
 }
 
+// Status returns status with which test item was finished, empty for unfinished test item
+func (ti *TestItem) Status() string {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	return ti.status
+}
+
+// Log sends log for specified test item, level must be one of Level... constants.
+// When client has LogBatcher, log is added to the batch and sent later
+func (ti *TestItem) Log(message, level string, attachment *Attachment) error {
+	return ti.client.SendLog(&LogEntry{
+		Message:    message,
+		Level:      Level(level),
+		Attachment: attachment,
+		ItemId:     ti.Id,
+	})
+}
+
 // Update updates launch
 func (ti *TestItem) Update(description string, tags []string) error {
-	url := fmt.Sprintf("%s/%s/item/%s/update", ti.client.Endpoint, ti.client.Project, ti.Id)
-	data := struct {
+	url := fmt.Sprintf("%s/%s/item/%s/update", ti.client.endpoint(), ti.client.Project, ti.Id)
+	var data interface{} = struct {
 		Description string   `json:"description"`
 		Tags        []string `json:"tags"`
 	}{description, tags}
+	if ti.client.features().Attributes {
+		data = struct {
+			Description string       `json:"description"`
+			Attributes  []*Attribute `json:"attributes"`
+		}{description, tagsToAttributes(tags)}
+	}
 
 	b, err := json.Marshal(&data)
 	if err != nil {
@@ -222,10 +258,10 @@ func (ti *TestItem) Update(description string, tags []string) error {
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := doRequest(req, ti.client.Token)
-	defer resp.Body.Close()
 	if err != nil {
 		return errors.Wrapf(err, "failed to execute PUT request to %s", req.URL)
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		return errors.Errorf("failed with status %s", resp.Status)
 	}
@@ -234,90 +270,102 @@ func (ti *TestItem) Update(description string, tags []string) error {
 	return nil
 }
 
+// Step reports fn as nested step of specified test item.
+// Step is finished as failed when fn returns an error or panics, panic is converted to returned error.
+// Step is skipped when before fixture of the test item or any of its ancestors failed
+func (ti *TestItem) Step(name string, fn func(step *TestItem) error) error {
+	step := NewTestItem(ti.launch, name, "", TestItemStep, nil, ti)
+	step.nested = true
+	if err := ti.FixtureErr(); err != nil {
+		return step.skip(err)
+	}
+	return step.run(fn)
+}
+
+// LinkExternalIssues links tickets from external bug tracking system to test item
+func (ti *TestItem) LinkExternalIssues(issues []*ExternalIssue) error {
+	return ti.client.LinkExternalIssues([]string{ti.Id}, issues)
+}
+
+// UnlinkExternalIssues unlinks tickets with specified ids from test item
+func (ti *TestItem) UnlinkExternalIssues(ticketIds []string) error {
+	return ti.client.UnlinkExternalIssues([]string{ti.Id}, ticketIds)
+}
+
 // Get activities for test item
 func (ti *TestItem) GetActivity() (*Activity, error) {
 	// TODO: Implement this
 	return nil, nil
 }
 
-// getReqForLogWithAttach creates request to perform log request with message and attachment
-func (ti *TestItem) getReqForLogWithAttach(message, level string, attachment *Attachment) (*http.Request, error) {
-	url := fmt.Sprintf("%s/%s/log", ti.client.Endpoint, ti.client.Project)
-	bodyBuf := &bytes.Buffer{}
-	bodyWriter := multipart.NewWriter(bodyBuf)
-
-	// json request part
-	h := make(textproto.MIMEHeader)
-	h.Set("Content-Disposition", `form-data; name="json_request_part"`)
-	h.Set("Content-Type", "application/json")
-	reqWriter, err := bodyWriter.CreatePart(h)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create form file")
-	}
-
-	f := &fileInfo{attachment.Name}
-	jsonReqPart := &jsonRequestPart{
-		{f, ti.Id, level, message, toTimestamp(time.Now())},
-	}
-	bs, err := json.Marshal(&jsonReqPart)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to marshal to JSON: %v", jsonReqPart)
-	}
-	reqReader := bytes.NewReader(bs)
+// addChild registers started child test item
+func (ti *TestItem) addChild(child *TestItem) {
+	ti.mu.Lock()
+	ti.children = append(ti.children, child)
+	ti.mu.Unlock()
+}
 
-	_, err = io.Copy(reqWriter, reqReader)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to copy reader")
-	}
+// removeChild unregisters finished child test item keeping its status for rollup
+func (ti *TestItem) removeChild(child *TestItem, status string) {
+	ti.mu.Lock()
+	ti.children = removeItem(ti.children, child)
+	ti.finished.add(status)
+	ti.mu.Unlock()
+}
 
-	// file
-	h = make(textproto.MIMEHeader)
-	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, "file", attachment.Name))
-	h.Set("Content-Type", attachment.MimeType)
+// closeAll finishes test item and all its descendants which are not finished yet, children first
+func (ti *TestItem) closeAll(status string) []error {
+	ti.mu.Lock()
+	children := make([]*TestItem, len(ti.children))
+	copy(children, ti.children)
+	ti.mu.Unlock()
 
-	fileWriter, err := bodyWriter.CreatePart(h)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create form file")
+	var errs []error
+	for _, child := range children {
+		errs = append(errs, child.closeAll(status)...)
 	}
-
-	_, err = io.Copy(fileWriter, attachment.Data)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to copy file writer")
+	if ti.Status() == "" {
+		if err := ti.Finish(status); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to finish test item %s", ti.Id))
+		}
 	}
+	return errs
+}
 
-	bodyWriter.Close()
-
-	req, err := http.NewRequest(http.MethodPost, url, bodyBuf)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to create POST request to %s", url)
+// run starts test item, executes fn and finishes test item with status depending on fn result
+func (ti *TestItem) run(fn func(item *TestItem) error) (err error) {
+	if err := ti.Start(); err != nil {
+		return errors.Wrapf(err, "failed to start %s", ti.Name)
 	}
 
-	req.Header.Set("Content-Type", bodyWriter.FormDataContentType())
-	return req, nil
+	// status stays failed when fn panics or calls runtime.Goexit (e.g. t.FailNow)
+	status := StatusFailed
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("%s panicked: %v", ti.Name, r)
+			ti.Log(fmt.Sprintf("%s\n%s", err, debug.Stack()), LevelFatal, nil)
+		}
+		if ferr := ti.Finish(status); ferr != nil && err == nil {
+			err = errors.Wrapf(ferr, "failed to finish %s", ti.Name)
+		}
+	}()
+
+	if err = fn(ti); err != nil {
+		ti.Log(err.Error(), LevelError, nil)
+		return err
+	}
+	status = StatusPassed
+	return nil
 }
 
-// getReqForLog creates request to perform log request with message
-func (ti *TestItem) getReqForLog(message, level string) (*http.Request, error) {
-	url := fmt.Sprintf("%s/%s/log", ti.client.Endpoint, ti.client.Project)
-	data := struct {
-		ItemId  string `json:"item_id"`
-		Message string `json:"message"`
-		Level   string `json:"level"`
-		Time    int64  `json:"time"`
-	}{ti.Id, message, level, toTimestamp(time.Now())}
-
-	b, err := json.Marshal(&data)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to marshal object, %v", data)
+// skip reports test item as skipped because of reason
+func (ti *TestItem) skip(reason error) error {
+	if err := ti.Start(); err != nil {
+		return errors.Wrapf(err, "failed to start %s", ti.Name)
 	}
-
-	r := bytes.NewReader(b)
-	req, err := http.NewRequest(http.MethodPost, url, r)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to create POST request to %s", url)
+	ti.Log(fmt.Sprintf("skipped: %s", reason), LevelWarn, nil)
+	if err := ti.Finish(StatusSkipped); err != nil {
+		return errors.Wrapf(err, "failed to finish %s", ti.Name)
 	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	return req, nil
+	return errors.Wrapf(reason, "%s skipped", ti.Name)
 }
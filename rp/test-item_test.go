@@ -1,6 +1,7 @@
 package rp
 
 import (
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -157,7 +158,7 @@ func TestLogTestItem(t *testing.T) {
 			d, err := ioutil.ReadAll(r.Body)
 			assert.NoError(t, err)
 
-			rx, _ := regexp.Compile(`\{\"item\_id\"\:\"item id\"\,\"message\"\:\"log message\"\,\"level\"\:\"log level\"\,\"time\"\:\d+\}`)
+			rx, _ := regexp.Compile(`\{\"item\_id\"\:\"item id\"\,\"message\"\:\"log message\"\,\"level\"\:\"info\"\,\"time\"\:\d+\}`)
 			assert.Regexp(t, rx, string(d))
 
 			w.WriteHeader(http.StatusCreated)
@@ -173,7 +174,7 @@ func TestLogTestItem(t *testing.T) {
 			},
 		}
 
-		err := ti.Log("log message", "log level", nil)
+		err := ti.Log("log message", LevelInfo, nil)
 		assert.NoError(t, err)
 	})
 
@@ -188,7 +189,7 @@ func TestLogTestItem(t *testing.T) {
 			assert.Contains(t, string(d), `Content-Disposition: form-data; name="json_request_part"`)
 			assert.Contains(t, string(d), `Content-Type: application/json`)
 
-			rx, _ := regexp.Compile(`\[\{\"file"\:\{\"name":\"test\-text\.txt"},\"item_id\"\:\"item id\"\,\"level\"\:\"log level\"\,\"message\"\:\"log message\"\,\"time\"\:\d+\}\]`)
+			rx, _ := regexp.Compile(`\[\{\"file"\:\{\"name":\"test\-text\.txt"},\"item_id\"\:\"item id\"\,\"level\"\:\"info\"\,\"message\"\:\"log message\"\,\"time\"\:\d+\}\]`)
 			assert.Regexp(t, rx, string(d))
 
 			assert.Contains(t, string(d), `Content-Disposition: form-data; name="file"; filename="test-text.txt"`)
@@ -207,7 +208,7 @@ func TestLogTestItem(t *testing.T) {
 			},
 		}
 
-		err := ti.Log("log message", "log level", &Attachment{
+		err := ti.Log("log message", LevelInfo, &Attachment{
 			Name:     "test-text.txt",
 			MimeType: "text/plain",
 			Data:     strings.NewReader("test text in file"),
@@ -229,7 +230,7 @@ func TestLogTestItem(t *testing.T) {
 			},
 		}
 
-		err := ti.Log("", "", nil)
+		err := ti.Log("", LevelInfo, nil)
 		assert.EqualError(t, err, "failed with status 500 Internal Server Error")
 	})
 }
@@ -276,3 +277,165 @@ func TestUpdateTestItem(t *testing.T) {
 		assert.EqualError(t, err, "failed with status 500 Internal Server Error")
 	})
 }
+
+func TestLinkExternalIssuesTestItem(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/test_project/item/issue/link", r.URL.Path)
+
+		d, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(d), `"testItemIds":["id123"]`)
+		assert.Contains(t, string(d), `"ticketId":"BUG-1"`)
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	ti := &TestItem{
+		Id: "id123",
+		client: &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		},
+	}
+
+	err := ti.LinkExternalIssues([]*ExternalIssue{{TicketId: "BUG-1"}})
+	assert.NoError(t, err)
+}
+
+func TestStepTestItem(t *testing.T) {
+	newServer := func(t *testing.T, status *string, logs *[]string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+
+			switch {
+			case r.Method == "POST" && r.URL.Path == "/test_project/item/parent123":
+				assert.Contains(t, string(d), `"name":"step name"`)
+				assert.Contains(t, string(d), `"type":"STEP"`)
+				assert.Contains(t, string(d), `"hasStats":false`)
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id": "step123"}`))
+			case r.Method == "PUT" && r.URL.Path == "/test_project/item/step123":
+				*status = string(d)
+			case r.Method == "POST" && r.URL.Path == "/test_project/log":
+				*logs = append(*logs, string(d))
+				w.WriteHeader(http.StatusCreated)
+			default:
+				t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			}
+		}))
+	}
+	newParent := func(s *httptest.Server) *TestItem {
+		c := &Client{
+			Endpoint:      s.URL,
+			Project:       "test_project",
+			ServerVersion: 5,
+		}
+		return &TestItem{
+			Id: "parent123",
+			launch: &Launch{
+				Id:     "id123",
+				client: c,
+			},
+			client: c,
+		}
+	}
+
+	t.Run("Successful step", func(t *testing.T) {
+		var status string
+		var logs []string
+		s := newServer(t, &status, &logs)
+		defer s.Close()
+
+		err := newParent(s).Step("step name", func(step *TestItem) error {
+			assert.Equal(t, "step123", step.Id)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Contains(t, status, `"status":"PASSED"`)
+		assert.Empty(t, logs)
+	})
+
+	t.Run("Failed step", func(t *testing.T) {
+		var status string
+		var logs []string
+		s := newServer(t, &status, &logs)
+		defer s.Close()
+
+		err := newParent(s).Step("step name", func(step *TestItem) error {
+			return errors.New("step error")
+		})
+		assert.EqualError(t, err, "step error")
+		assert.Contains(t, status, `"status":"FAILED"`)
+		assert.Len(t, logs, 1)
+		assert.Contains(t, logs[0], `"message":"step error","level":"error"`)
+	})
+
+	t.Run("Panicked step", func(t *testing.T) {
+		var status string
+		var logs []string
+		s := newServer(t, &status, &logs)
+		defer s.Close()
+
+		err := newParent(s).Step("step name", func(step *TestItem) error {
+			panic("boom")
+		})
+		assert.EqualError(t, err, "step name panicked: boom")
+		assert.Contains(t, status, `"status":"FAILED"`)
+		assert.Len(t, logs, 1)
+		assert.Contains(t, logs[0], `"level":"fatal"`)
+	})
+}
+
+func TestFinishTestItemWithRollup(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(d), `"status":"FAILED"`)
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	ti := &TestItem{
+		Id: "id123",
+		client: &Client{
+			Endpoint:     s.URL,
+			Project:      "test_project",
+			StatusRollup: true,
+		},
+	}
+	ti.addChild(&TestItem{status: StatusPassed})
+	ti.addChild(&TestItem{status: StatusFailed})
+
+	err := ti.Finish("")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusFailed, ti.Status())
+}
+
+func TestFinishedItemsReleased(t *testing.T) {
+	var finished []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(d), `"status":"FAILED"`)
+		finished = append(finished, r.URL.Path)
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := &Client{Endpoint: s.URL, Project: "p", StatusRollup: true}
+	l := &Launch{Id: "l1", client: c}
+	c.addLaunch(l)
+	root := &TestItem{Id: "root", launch: l, client: c}
+	l.addItem(root)
+	child := &TestItem{Id: "child", launch: l, client: c, Parent: root}
+	root.addChild(child)
+
+	assert.NoError(t, child.Finish(StatusFailed))
+	assert.Empty(t, root.children)
+	assert.NoError(t, root.Finish(""))
+	assert.Empty(t, l.items)
+	assert.NoError(t, l.Finish(""))
+	assert.Empty(t, c.launches)
+	assert.Equal(t, []string{"/p/item/child", "/p/item/root", "/p/launch/l1/finish"}, finished)
+}
@@ -0,0 +1,248 @@
+package rp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ProjectRoleOperator = "OPERATOR"
+	ProjectRoleCustomer = "CUSTOMER"
+	ProjectRoleMember   = "MEMBER"
+	ProjectRoleManager  = "PROJECT_MANAGER"
+
+	AccountRoleUser          = "USER"
+	AccountRoleAdministrator = "ADMINISTRATOR"
+)
+
+// AssignedProject defines role of user on project
+type AssignedProject struct {
+	ProjectRole string `json:"projectRole"`
+	EntryType   string `json:"entryType"`
+}
+
+// UserResource defines user profile
+type UserResource struct {
+	Login            string                      `json:"userId"`
+	Email            string                      `json:"email"`
+	FullName         string                      `json:"fullName"`
+	AccountType      string                      `json:"accountType"`
+	UserRole         string                      `json:"userRole"`
+	DefaultProject   string                      `json:"defaultProject"`
+	AssignedProjects map[string]*AssignedProject `json:"assignedProjects"`
+}
+
+// UserPage defines page of users
+type UserPage = Page[*UserResource]
+
+// NewUser defines user created by administrator
+type NewUser struct {
+	Login          string `json:"login"`
+	Password       string `json:"password"`
+	FullName       string `json:"fullName"`
+	Email          string `json:"email"`
+	AccountRole    string `json:"accountRole"`
+	ProjectRole    string `json:"projectRole"`
+	DefaultProject string `json:"defaultProject"`
+}
+
+// UserInvitation defines invitation sent to user
+type UserInvitation struct {
+	BackLink string `json:"backLink"`
+	Bid      string `json:"bid"`
+	Message  string `json:"message"`
+}
+
+// GetCurrentUser gets profile of user owning client token
+func (c *Client) GetCurrentUser() (*UserResource, error) {
+	url := fmt.Sprintf("%s/user", c.endpoint())
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't create GET request for %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute GET request for %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	var u *UserResource
+	if err := json.NewDecoder(resp.Body).Decode(&u); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response for user")
+	}
+	return u, nil
+}
+
+// GetProjectMembers gets members of client project matching query
+func (c *Client) GetProjectMembers(q *Query) (*UserPage, error) {
+	return c.getUsers(fmt.Sprintf("%s/project/%s/users", c.endpoint(), c.Project), q, nil)
+}
+
+// IterProjectMembers iterates over members of client project matching query
+func (c *Client) IterProjectMembers(q *Query) *Iterator[*UserResource] {
+	return NewIterator(func(number int) (*UserPage, error) {
+		return c.GetProjectMembers(q.withPage(number))
+	})
+}
+
+// SearchUsers gets users with login, name or email matching term
+func (c *Client) SearchUsers(term string, q *Query) (*UserPage, error) {
+	return c.getUsers(fmt.Sprintf("%s/user/search", c.endpoint()), q, url.Values{"term": {term}})
+}
+
+// IterUsers iterates over users with login, name or email matching term
+func (c *Client) IterUsers(term string, q *Query) *Iterator[*UserResource] {
+	return NewIterator(func(number int) (*UserPage, error) {
+		return c.SearchUsers(term, q.withPage(number))
+	})
+}
+
+// AssignUsers assigns users to client project with roles keyed by login
+func (c *Client) AssignUsers(roles map[string]string) error {
+	data := struct {
+		UserNames map[string]string `json:"userNames"`
+	}{roles}
+	return c.updateMembers("assign", data)
+}
+
+// UnassignUsers unassigns users with specified logins from client project
+func (c *Client) UnassignUsers(logins ...string) error {
+	data := struct {
+		UserNames []string `json:"userNames"`
+	}{logins}
+	return c.updateMembers("unassign", data)
+}
+
+// CreateUser creates user, requires administrator account
+func (c *Client) CreateUser(u *NewUser) error {
+	url := fmt.Sprintf("%s/user", c.endpoint())
+
+	b, err := json.Marshal(u)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal object %v", u)
+	}
+
+	r := bytes.NewReader(b)
+	req, err := http.NewRequest(http.MethodPost, url, r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create POST request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute POST request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+	return nil
+}
+
+// InviteUser sends invitation to client project with specified role to email
+func (c *Client) InviteUser(email, role string) (*UserInvitation, error) {
+	url := fmt.Sprintf("%s/user/bid", c.endpoint())
+	data := struct {
+		Email          string `json:"email"`
+		Role           string `json:"role"`
+		DefaultProject string `json:"defaultProject"`
+	}{email, role, c.Project}
+
+	b, err := json.Marshal(&data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal object %v", data)
+	}
+
+	r := bytes.NewReader(b)
+	req, err := http.NewRequest(http.MethodPost, url, r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create POST request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute POST request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return nil, errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	var inv *UserInvitation
+	if err := json.NewDecoder(resp.Body).Decode(&inv); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode response from %s", req.URL)
+	}
+	return inv, nil
+}
+
+// getUsers gets page of users from list endpoint
+func (c *Client) getUsers(base string, q *Query, extra url.Values) (*UserPage, error) {
+	url, err := listURL(base, q, extra)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't create GET request for %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute GET request for %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	var up *UserPage
+	if err := json.NewDecoder(resp.Body).Decode(&up); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response for users")
+	}
+	return up, nil
+}
+
+// updateMembers sends assign or unassign request for client project
+func (c *Client) updateMembers(action string, data interface{}) error {
+	url := fmt.Sprintf("%s/project/%s/%s", c.endpoint(), c.Project, action)
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal object %v", data)
+	}
+
+	r := bytes.NewReader(b)
+	req, err := http.NewRequest(http.MethodPut, url, r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create PUT request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute PUT request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+	return nil
+}
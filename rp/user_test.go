@@ -0,0 +1,187 @@
+package rp
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCurrentUser(t *testing.T) {
+	t.Run("Successful get", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/user", r.URL.Path)
+			assert.Equal(t, "GET", r.Method)
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+			w.Write([]byte(`{"userId":"jdoe","email":"jdoe@example.com","fullName":"John Doe","accountType":"INTERNAL","userRole":"USER",` +
+				`"assignedProjects":{"test_project":{"projectRole":"MEMBER","entryType":"INTERNAL"}}}`))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+			Token:    "token",
+		}
+
+		u, err := c.GetCurrentUser()
+		assert.NoError(t, err)
+		assert.Equal(t, &UserResource{
+			Login:       "jdoe",
+			Email:       "jdoe@example.com",
+			FullName:    "John Doe",
+			AccountType: "INTERNAL",
+			UserRole:    AccountRoleUser,
+			AssignedProjects: map[string]*AssignedProject{
+				"test_project": {ProjectRole: ProjectRoleMember, EntryType: "INTERNAL"},
+			},
+		}, u)
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+		}
+
+		u, err := c.GetCurrentUser()
+		assert.Nil(t, u)
+		assert.EqualError(t, err, "failed with status 401 Unauthorized")
+	})
+}
+
+func TestProjectMembers(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		switch r.URL.Path {
+		case "/project/test_project/users":
+			assert.Equal(t, "jd", r.URL.Query().Get("filter.cnt.user"))
+			w.Write([]byte(`{"content":[{"userId":"jdoe"}],"page":{"number":1,"size":20,"totalElements":1,"totalPages":1}}`))
+		case "/user/search":
+			assert.Equal(t, "doe", r.URL.Query().Get("term"))
+			w.Write([]byte(`{"content":[{"userId":"jdoe"},{"userId":"adoe"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := &Client{
+		Endpoint: s.URL,
+		Project:  "test_project",
+	}
+
+	up, err := c.GetProjectMembers(NewQuery().Cnt(FilterFieldUser, "jd"))
+	assert.NoError(t, err)
+	assert.Equal(t, "jdoe", up.Content[0].Login)
+
+	users, err := Collect(c.IterUsers("doe", nil), 0)
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+}
+
+func TestAssignUsers(t *testing.T) {
+	var requests []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method)
+		d, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		requests = append(requests, r.URL.Path+" "+string(d))
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := &Client{
+		Endpoint: s.URL,
+		Project:  "test_project",
+	}
+
+	assert.NoError(t, c.AssignUsers(map[string]string{"jdoe": ProjectRoleManager}))
+	assert.NoError(t, c.UnassignUsers("jdoe", "adoe"))
+	assert.Equal(t, []string{
+		`/project/test_project/assign {"userNames":{"jdoe":"PROJECT_MANAGER"}}`,
+		`/project/test_project/unassign {"userNames":["jdoe","adoe"]}`,
+	}, requests)
+}
+
+func TestCreateUser(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/user", r.URL.Path)
+		assert.Equal(t, "POST", r.Method)
+
+		d, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"login":"jdoe","password":"secret","fullName":"John Doe","email":"jdoe@example.com",`+
+			`"accountRole":"USER","projectRole":"MEMBER","defaultProject":"test_project"}`, string(d))
+		w.WriteHeader(http.StatusCreated)
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := &Client{
+		Endpoint: s.URL,
+	}
+
+	err := c.CreateUser(&NewUser{
+		Login:          "jdoe",
+		Password:       "secret",
+		FullName:       "John Doe",
+		Email:          "jdoe@example.com",
+		AccountRole:    AccountRoleUser,
+		ProjectRole:    ProjectRoleMember,
+		DefaultProject: "test_project",
+	})
+	assert.NoError(t, err)
+}
+
+func TestInviteUser(t *testing.T) {
+	t.Run("Successful invite", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/user/bid", r.URL.Path)
+			assert.Equal(t, "POST", r.Method)
+
+			d, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, `{"email":"jdoe@example.com","role":"OPERATOR","defaultProject":"test_project"}`, string(d))
+
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"backLink":"http://rp/ui/#registration?uuid=b1","bid":"b1","message":"Invitation sent"}`))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		}
+
+		inv, err := c.InviteUser("jdoe@example.com", ProjectRoleOperator)
+		assert.NoError(t, err)
+		assert.Equal(t, "b1", inv.Bid)
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+		}
+
+		inv, err := c.InviteUser("jdoe@example.com", ProjectRoleOperator)
+		assert.Nil(t, inv)
+		assert.EqualError(t, err, "failed with status 403 Forbidden")
+	})
+}
@@ -0,0 +1,265 @@
+package rp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	WidgetLaunchStatistics       = "statisticTrend"
+	WidgetOverallStatistics      = "overallStatistics"
+	WidgetLaunchesDuration       = "launchesDurationChart"
+	WidgetLaunchesComparison     = "launchesComparisonChart"
+	WidgetLaunchesTable          = "launchesTable"
+	WidgetInvestigatedPercentage = "investigatedTrend"
+	WidgetPassingRatePerLaunch   = "passingRatePerLaunch"
+	WidgetPassingRateSummary     = "passingRateSummary"
+	WidgetFlakyTestCases         = "flakyTestCases"
+	WidgetMostFailedTests        = "topTestCases"
+	WidgetMostTimeConsuming      = "mostTimeConsuming"
+	WidgetFailedCasesTrend       = "bugTrend"
+	WidgetTestCasesGrowth        = "casesTrend"
+	WidgetUniqueBugs             = "uniqueBugTable"
+	WidgetNonPassedCases         = "notPassed"
+	WidgetActivityStream         = "activityStream"
+	WidgetCumulativeTrend        = "cumulative"
+	WidgetProductStatus          = "productStatus"
+)
+
+// WidgetContentParameters defines parameters of widget content
+type WidgetContentParameters struct {
+	ContentFields []string               `json:"contentFields"`
+	ItemsCount    int                    `json:"itemsCount"`
+	WidgetOptions map[string]interface{} `json:"widgetOptions,omitempty"`
+}
+
+// AppliedFilter defines filter bound to widget
+type AppliedFilter struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// WidgetResource defines widget with its type, content parameters and filters
+type WidgetResource struct {
+	Id                string                   `json:"id,omitempty"`
+	Name              string                   `json:"name"`
+	Description       string                   `json:"description"`
+	WidgetType        string                   `json:"widgetType"`
+	Share             bool                     `json:"share"`
+	Owner             string                   `json:"owner,omitempty"`
+	FilterIds         []string                 `json:"filterIds,omitempty"`
+	AppliedFilters    []*AppliedFilter         `json:"appliedFilters,omitempty"`
+	ContentParameters *WidgetContentParameters `json:"contentParameters"`
+}
+
+// WidgetContent defines widget with content calculated by ReportPortal
+type WidgetContent struct {
+	WidgetResource
+	Content json.RawMessage `json:"content"`
+}
+
+// LaunchStatistics defines statistics of launch in widget content,
+// values are keyed by content fields (e.g. statistics$executions$failed)
+type LaunchStatistics struct {
+	Id     string            `json:"id"`
+	Number int               `json:"number"`
+	Name   string            `json:"name"`
+	Values map[string]string `json:"values"`
+}
+
+// PassingRate defines content of passing rate widgets
+type PassingRate struct {
+	Passed int `json:"passed"`
+	Total  int `json:"total"`
+}
+
+// TestCaseStatistics defines test case in content of flaky and most failed test cases widgets
+type TestCaseStatistics struct {
+	UniqueId   string
+	Name       string
+	Count      int
+	Total      int
+	Percentage float64
+}
+
+// CreateWidget creates new widget. Returns id of created widget
+func (c *Client) CreateWidget(w *WidgetResource) (string, error) {
+	url := fmt.Sprintf("%s/%s/widget", c.endpoint(), c.Project)
+
+	b, err := json.Marshal(w)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to marshal object %v", w)
+	}
+
+	r := bytes.NewReader(b)
+	req, err := http.NewRequest(http.MethodPost, url, r)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create POST request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to execute POST request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	v := struct {
+		Id string `json:"id"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return "", errors.Wrapf(err, "failed to decode response from %s", req.URL)
+	}
+	return v.Id, nil
+}
+
+// UpdateWidget updates widget with id of w
+func (c *Client) UpdateWidget(w *WidgetResource) error {
+	url := fmt.Sprintf("%s/%s/widget/%s", c.endpoint(), c.Project, w.Id)
+
+	b, err := json.Marshal(w)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal object %v", w)
+	}
+
+	r := bytes.NewReader(b)
+	req, err := http.NewRequest(http.MethodPut, url, r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create PUT request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute PUT request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+	return nil
+}
+
+// DeleteWidget deletes widget with specified id, widget must be removed from dashboards before
+func (c *Client) DeleteWidget(id string) error {
+	url := fmt.Sprintf("%s/%s/widget/%s", c.endpoint(), c.Project, id)
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create DELETE request for %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute DELETE request %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+	return nil
+}
+
+// GetWidgetContent gets widget with specified id and its content
+func (c *Client) GetWidgetContent(id string) (*WidgetContent, error) {
+	url := fmt.Sprintf("%s/%s/widget/%s", c.endpoint(), c.Project, id)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't create GET request for %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, c.Token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute GET request for %s", req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	var wc *WidgetContent
+	if err := json.NewDecoder(resp.Body).Decode(&wc); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response for widget")
+	}
+	return wc, nil
+}
+
+// Decode decodes raw widget content to v
+func (wc *WidgetContent) Decode(v interface{}) error {
+	if err := json.Unmarshal(wc.Content, v); err != nil {
+		return errors.Wrapf(err, "failed to decode content of widget %s", wc.Id)
+	}
+	return nil
+}
+
+// Launches returns launch statistics from content of launch statistics, overall statistics,
+// launches duration, investigated percentage and other launch based widgets
+func (wc *WidgetContent) Launches() ([]*LaunchStatistics, error) {
+	v := struct {
+		Result []*LaunchStatistics `json:"result"`
+	}{}
+	if err := wc.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v.Result, nil
+}
+
+// PassingRate returns content of passing rate widgets
+func (wc *WidgetContent) PassingRate() (*PassingRate, error) {
+	var pr *PassingRate
+	if err := wc.Decode(&pr); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// TestCases returns test cases from content of flaky and most failed test cases widgets
+func (wc *WidgetContent) TestCases() ([]*TestCaseStatistics, error) {
+	type testCase struct {
+		UniqueId   string  `json:"uniqueId"`
+		Name       string  `json:"name"`
+		ItemName   string  `json:"itemName"`
+		FlakyCount int     `json:"flakyCount"`
+		Criteria   int     `json:"criteria"`
+		Total      int     `json:"total"`
+		Percentage float64 `json:"percentage"`
+	}
+	v := struct {
+		Flaky  []*testCase `json:"flaky"`
+		Result []*testCase `json:"result"`
+	}{}
+	if err := wc.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var res []*TestCaseStatistics
+	for _, tc := range append(v.Flaky, v.Result...) {
+		ts := &TestCaseStatistics{
+			UniqueId:   tc.UniqueId,
+			Name:       tc.Name,
+			Count:      tc.Criteria,
+			Total:      tc.Total,
+			Percentage: tc.Percentage,
+		}
+		if tc.ItemName != "" {
+			ts.Name = tc.ItemName
+		}
+		if tc.FlakyCount != 0 {
+			ts.Count = tc.FlakyCount
+		}
+		res = append(res, ts)
+	}
+	return res, nil
+}
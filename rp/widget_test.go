@@ -0,0 +1,225 @@
+package rp
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateWidget(t *testing.T) {
+	t.Run("Successful create", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/test_project/widget", r.URL.Path)
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			d, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, `{"name":"failures","description":"","widgetType":"statisticTrend","share":true,"filterIds":["f1"],`+
+				`"contentParameters":{"contentFields":["statistics$executions$failed"],"itemsCount":50,"widgetOptions":{"timeline":"launch"}}}`, string(d))
+
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": "wid1"}`))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		}
+
+		id, err := c.CreateWidget(&WidgetResource{
+			Name:       "failures",
+			WidgetType: WidgetLaunchStatistics,
+			Share:      true,
+			FilterIds:  []string{"f1"},
+			ContentParameters: &WidgetContentParameters{
+				ContentFields: []string{"statistics$executions$failed"},
+				ItemsCount:    50,
+				WidgetOptions: map[string]interface{}{"timeline": "launch"},
+			},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "wid1", id)
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+		}
+
+		id, err := c.CreateWidget(&WidgetResource{})
+		assert.Empty(t, id)
+		assert.EqualError(t, err, "failed with status 400 Bad Request")
+	})
+}
+
+func TestUpdateWidget(t *testing.T) {
+	t.Run("Successful update", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/test_project/widget/wid1", r.URL.Path)
+			assert.Equal(t, "PUT", r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		}
+
+		err := c.UpdateWidget(&WidgetResource{Id: "wid1", Name: "renamed"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+		}
+
+		err := c.UpdateWidget(&WidgetResource{Id: "wid1"})
+		assert.EqualError(t, err, "failed with status 500 Internal Server Error")
+	})
+}
+
+func TestDeleteWidget(t *testing.T) {
+	t.Run("Successful delete", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/test_project/widget/wid1", r.URL.Path)
+			assert.Equal(t, "DELETE", r.Method)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		}
+
+		assert.NoError(t, c.DeleteWidget("wid1"))
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		c := &Client{
+			Endpoint: s.URL,
+		}
+
+		assert.EqualError(t, c.DeleteWidget("wid1"), "failed with status 404 Not Found")
+	})
+}
+
+func TestGetWidgetContent(t *testing.T) {
+	newClient := func(s *httptest.Server) *Client {
+		return &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		}
+	}
+	newServer := func(response string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/test_project/widget/wid1", r.URL.Path)
+			assert.Equal(t, "GET", r.Method)
+			w.Write([]byte(response))
+		}))
+	}
+
+	t.Run("Launch statistics", func(t *testing.T) {
+		s := newServer(`{
+			"id": "wid1",
+			"name": "failures",
+			"widgetType": "statisticTrend",
+			"appliedFilters": [{"id": "f1", "name": "nightly"}],
+			"contentParameters": {"contentFields": ["statistics$executions$failed"], "itemsCount": 50},
+			"content": {"result": [{"id": "l1", "number": 3, "name": "nightly", "values": {"statistics$executions$failed": "2"}}]}
+		}`)
+		defer s.Close()
+
+		wc, err := newClient(s).GetWidgetContent("wid1")
+		assert.NoError(t, err)
+		assert.Equal(t, "failures", wc.Name)
+		assert.Equal(t, WidgetLaunchStatistics, wc.WidgetType)
+		assert.Equal(t, []*AppliedFilter{{"f1", "nightly"}}, wc.AppliedFilters)
+
+		ls, err := wc.Launches()
+		assert.NoError(t, err)
+		assert.Equal(t, []*LaunchStatistics{
+			{
+				Id:     "l1",
+				Number: 3,
+				Name:   "nightly",
+				Values: map[string]string{"statistics$executions$failed": "2"},
+			},
+		}, ls)
+	})
+
+	t.Run("Passing rate", func(t *testing.T) {
+		s := newServer(`{"id": "wid1", "widgetType": "passingRateSummary", "content": {"passed": 9, "total": 10}}`)
+		defer s.Close()
+
+		wc, err := newClient(s).GetWidgetContent("wid1")
+		assert.NoError(t, err)
+
+		pr, err := wc.PassingRate()
+		assert.NoError(t, err)
+		assert.Equal(t, &PassingRate{Passed: 9, Total: 10}, pr)
+	})
+
+	t.Run("Flaky test cases", func(t *testing.T) {
+		s := newServer(`{"id": "wid1", "widgetType": "flakyTestCases", "content": {"flaky": [
+			{"uniqueId": "u1", "itemName": "test login", "flakyCount": 3, "total": 10, "percentage": 30}
+		]}}`)
+		defer s.Close()
+
+		wc, err := newClient(s).GetWidgetContent("wid1")
+		assert.NoError(t, err)
+
+		tcs, err := wc.TestCases()
+		assert.NoError(t, err)
+		assert.Equal(t, []*TestCaseStatistics{{"u1", "test login", 3, 10, 30}}, tcs)
+	})
+
+	t.Run("Wrong content", func(t *testing.T) {
+		s := newServer(`{"id": "wid1", "content": []}`)
+		defer s.Close()
+
+		wc, err := newClient(s).GetWidgetContent("wid1")
+		assert.NoError(t, err)
+
+		_, err = wc.PassingRate()
+		assert.Error(t, err)
+	})
+
+	t.Run("Wrong status code", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer s.Close()
+
+		wc, err := newClient(s).GetWidgetContent("wid1")
+		assert.Nil(t, wc)
+		assert.EqualError(t, err, "failed with status 404 Not Found")
+	})
+}
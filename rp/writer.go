@@ -0,0 +1,26 @@
+package rp
+
+import (
+	"strings"
+)
+
+// LogWriter defines io.Writer which sends every written message as log of test item,
+// it can be used as output of standard log package
+type LogWriter struct {
+	item  *TestItem
+	level string
+}
+
+// NewLogWriter creates new writer which sends logs with specified level to test item
+func NewLogWriter(item *TestItem, level string) *LogWriter {
+	return &LogWriter{item, level}
+}
+
+// Write sends p without trailing new line as log message
+func (lw *LogWriter) Write(p []byte) (int, error) {
+	message := strings.TrimSuffix(string(p), "\n")
+	if err := lw.item.Log(message, lw.level, nil); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,39 @@
+package rp
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogWriter(t *testing.T) {
+	var logs []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		logs = append(logs, string(d))
+		w.WriteHeader(http.StatusCreated)
+	})
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	ti := &TestItem{
+		Id: "id123",
+		client: &Client{
+			Endpoint: s.URL,
+			Project:  "test_project",
+		},
+	}
+
+	l := log.New(NewLogWriter(ti, LevelWarn), "prefix: ", 0)
+	l.Println("first")
+	l.Print("second")
+
+	assert.Len(t, logs, 2)
+	assert.Contains(t, logs[0], `"message":"prefix: first","level":"warn"`)
+	assert.Contains(t, logs[1], `"message":"prefix: second","level":"warn"`)
+}